@@ -21,12 +21,16 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
 )
 
 const (
+	// escapedDollarToken temporarily stands in for an escaped dollar sign
+	// (`\$`) in manifest content while variables are expanded.
+	escapedDollarToken = "\x00gdt-kube-escaped-dollar\x00"
 	// fieldManagerName is the identifier for the field manager we specify in
-	// Apply requests.
+	// Apply and Patch requests.
 	fieldManagerName = "gdt-kube"
 )
 
@@ -34,10 +38,12 @@ const (
 // test.
 type Action struct {
 	// Create is a string containing a file path or raw YAML content describing
-	// a Kubernetes resource to call `kubectl create` with.
+	// a Kubernetes resource to call `kubectl create` with. Variable
+	// references in the content are replaced before the resource is created.
 	Create string `yaml:"create,omitempty"`
 	// Apply is a string containing a file path or raw YAML content describing
-	// a Kubernetes resource to call `kubectl apply` with.
+	// a Kubernetes resource to call `kubectl apply` with. Variable references
+	// in the content are replaced before the resource is applied.
 	Apply string `yaml:"apply,omitempty"`
 	// Delete is a string or object containing arguments to `kubectl delete`.
 	//
@@ -63,6 +69,73 @@ type Action struct {
 	// - an object with a `type` and optional `labels` field containing a label
 	//   selector that should be used to select that `type` of resource.
 	Get *ResourceIdentifier `yaml:"get,omitempty"`
+	// Patch is an object describing a patch to send to the Kubernetes API
+	// server for one or more resources, similar to `kubectl patch`.
+	//
+	// It contains a `target` field with a resource identifier (same forms as
+	// the `get` field), an optional `type` field that must be one of
+	// `strategic` (the default), `merge` or `json` and a `patch` field
+	// containing the patch body as a file path, a raw YAML/JSON string or an
+	// inline object.
+	Patch *PatchAction `yaml:"patch,omitempty"`
+	// Exec is an object describing a command to execute in a container of a
+	// Pod, similar to `kubectl exec`.
+	//
+	// It contains a `target` field identifying the Pod (or a workload whose
+	// Pods will be selected), an optional `container` field and a `command`
+	// field with either a string or a list of strings.
+	Exec *ExecAction `yaml:"exec,omitempty"`
+	// Logs is an object describing the container logs to fetch from one or
+	// more Pods, similar to `kubectl logs`.
+	//
+	// It contains a `target` field identifying the Pod(s) (or a workload whose
+	// Pods will be selected) and optional `container`, `all-containers`,
+	// `previous`, `since` and `tail` fields.
+	Logs *LogsAction `yaml:"logs,omitempty"`
+	// Describe is a string or object identifying a single resource to
+	// describe, similar to `kubectl describe`. It takes the same forms as the
+	// `get` field but must include a resource name.
+	//
+	// The result is a composite document with the described `object`, the
+	// `events` whose involvedObject is the described object, the `children`
+	// owned by the object and, for workloads, the selected `pods` and a
+	// `podStatus` summary of their phases.
+	Describe *ResourceIdentifier `yaml:"describe,omitempty"`
+	// Wait is an object describing a wait for one or more resources to reach
+	// a desired state, similar to `kubectl wait`.
+	//
+	// It contains a `target` field with a resource identifier (same forms as
+	// the `get` field) and a `for` field with one of
+	// `condition=<type>[=<status>]`, `delete` or
+	// `jsonpath=<expression>[=<value>]`.
+	Wait *WaitAction `yaml:"wait,omitempty"`
+	// Watch is an object describing a watch on one or more resources that
+	// records the ADDED, MODIFIED and DELETED events for those resources,
+	// similar to `kubectl get --watch`.
+	//
+	// It contains a `target` field with a resource identifier (same forms as
+	// the `get` field), an optional `duration` field and an optional `until`
+	// field with a terminal predicate that stops the watch.
+	Watch *WatchAction `yaml:"watch,omitempty"`
+	// Events is an object describing the core/v1 and events.k8s.io/v1 Events
+	// to fetch for one or more objects.
+	//
+	// It contains a `target` field with a resource identifier (same forms as
+	// the `get` field) and an optional `since` duration limiting the Events
+	// to those that occurred recently.
+	Events *EventsAction `yaml:"events,omitempty"`
+	// dryRun contains the dry-run stages sent with the create, apply, patch
+	// and delete requests. It is set from the Spec's `dry-run` value when the
+	// Spec is evaluated.
+	dryRun []string
+	// fieldManager is the field manager sent with the create, apply and patch
+	// requests. It is set from the Spec's `field-manager` value when the Spec
+	// is evaluated. If empty, "gdt-kube" is used for apply and patch requests.
+	fieldManager string
+	// force is whether apply requests force the apply through any conflicts
+	// with other field managers. It is set from the Spec's `force` value when
+	// the Spec is evaluated. If nil, conflicts are forced.
+	force *bool
 }
 
 // getCommand returns a string of the command that the action will end up
@@ -80,6 +153,27 @@ func (a *Action) getCommand() string {
 	if a.Apply != "" {
 		return "apply"
 	}
+	if a.Patch != nil {
+		return "patch"
+	}
+	if a.Exec != nil {
+		return "exec"
+	}
+	if a.Logs != nil {
+		return "logs"
+	}
+	if a.Describe != nil {
+		return "describe"
+	}
+	if a.Wait != nil {
+		return "wait"
+	}
+	if a.Watch != nil {
+		return "watch"
+	}
+	if a.Events != nil {
+		return "events"
+	}
 	return "unknown"
 }
 
@@ -89,8 +183,15 @@ func (a *Action) getCommand() string {
 // call.
 //
 // `out` will be filled with the contents of the command's output, if any. When
-// the command is a Get, `out` will be a `*unstructured.Unstructured`. When the
-// command is a List, `out` will be a `*unstructured.UnstructuredList`.
+// the command is a Get or a Patch of a single named resource, `out` will be a
+// `*unstructured.Unstructured`. When the command is a List or a Patch of
+// resources selected by labels, `out` will be a
+// `*unstructured.UnstructuredList`. When the command is an Exec, `out` will be
+// an `*execOutput`. When the command is Logs, `out` will be a `*logsOutput`.
+// When the command is a Describe, `out` will be a `*describeOutput`. When the
+// command is a Wait, `out` will be a `*waitOutput`. When the command is a
+// Watch, `out` will be a `*watchOutput`. When the command is Events, `out` will
+// be an `*eventsOutput`.
 func (a *Action) Do(
 	ctx context.Context,
 	c *connection,
@@ -98,6 +199,9 @@ func (a *Action) Do(
 	out *interface{},
 ) error {
 	cmd := a.getCommand()
+	if len(a.dryRun) > 0 {
+		debug.Printf(ctx, "kube.%s: dry-run: %v", cmd, a.dryRun)
+	}
 
 	switch cmd {
 	case "get":
@@ -108,11 +212,68 @@ func (a *Action) Do(
 		return a.delete(ctx, c, ns)
 	case "apply":
 		return a.apply(ctx, c, ns, out)
+	case "patch":
+		return a.patch(ctx, c, ns, out)
+	case "exec":
+		return a.exec(ctx, c, ns, out)
+	case "logs":
+		return a.logs(ctx, c, ns, out)
+	case "describe":
+		return a.describe(ctx, c, ns, out)
+	case "wait":
+		return a.wait(ctx, c, ns, out)
+	case "watch":
+		return a.watch(ctx, c, ns, out)
+	case "events":
+		return a.events(ctx, c, ns, out)
 	default:
 		return fmt.Errorf("unknown command")
 	}
 }
 
+// getFieldManager returns the field manager sent with Apply() and Patch()
+// calls
+func (a *Action) getFieldManager() string {
+	if a.fieldManager != "" {
+		return a.fieldManager
+	}
+	return fieldManagerName
+}
+
+// createOptions returns the options sent with Create() calls
+func (a *Action) createOptions() metav1.CreateOptions {
+	// NOTE: Unlike Apply() and Patch(), we only send a field manager with
+	// Create() calls when the test author asked for one, leaving the API
+	// server to choose the default otherwise.
+	return metav1.CreateOptions{
+		FieldManager: a.fieldManager,
+		DryRun:       a.dryRun,
+	}
+}
+
+// applyOptions returns the options sent with Apply() calls
+func (a *Action) applyOptions() metav1.ApplyOptions {
+	return metav1.ApplyOptions{
+		FieldManager: a.getFieldManager(),
+		Force:        a.force == nil || *a.force,
+		DryRun:       a.dryRun,
+	}
+}
+
+// patchOptions returns the options sent with Patch() calls
+func (a *Action) patchOptions() metav1.PatchOptions {
+	return metav1.PatchOptions{
+		FieldManager: a.getFieldManager(),
+		DryRun:       a.dryRun,
+	}
+}
+
+// deleteOptions returns the options sent with Delete() and
+// DeleteCollection() calls
+func (a *Action) deleteOptions() metav1.DeleteOptions {
+	return metav1.DeleteOptions{DryRun: a.dryRun}
+}
+
 // get executes either a List() or a Get() call against the Kubernetes API
 // server, returning any error returned from the client call and populating
 // `out` with the response value.
@@ -137,7 +298,7 @@ func (a *Action) get(
 	}
 	name := a.Get.Name
 	if name == "" {
-		list, err := a.doList(ctx, c, res, ns)
+		list, err := a.doList(ctx, c, res, ns, a.Get.Labels)
 		if err == nil {
 			*out = list
 		}
@@ -159,17 +320,18 @@ func (a *Action) get(
 	}
 }
 
-// doList performs the List() call for a supplied resource kind
+// doList performs the List() call for a supplied resource kind and optional
+// label selector
 func (a *Action) doList(
 	ctx context.Context,
 	c *connection,
 	res schema.GroupVersionResource,
 	ns string,
+	withlabels map[string]string,
 ) (*unstructured.UnstructuredList, error) {
 	resName := res.Resource
 	labelSelString := ""
 	opts := metav1.ListOptions{}
-	withlabels := a.Get.Labels
 	if withlabels != nil {
 		// We already validated the label selector during parse-time
 		labelsStr := labels.Set(withlabels).String()
@@ -233,35 +395,20 @@ func (a *Action) create(
 	ns string,
 	out *interface{},
 ) error {
-	var err error
-	var r io.Reader
-	if probablyFilePath(a.Create) {
-		path := a.Create
-		f, err := os.Open(path)
-		if err != nil {
-			// This should never happen because we check during parse time
-			// whether the file can be opened.
-			rterr := fmt.Errorf("%w: %s", api.RuntimeError, err)
-			return rterr
-		}
-		defer f.Close()
-		r = f
-	} else {
-		// Consider the string to be YAML/JSON content and marshal that into an
-		// unstructured.Unstructured that we then pass to Create()
-		r = strings.NewReader(a.Create)
-	}
-
 	// This is what we return to the caller via the `out` param. It contains
 	// all of the created objects. This is NOT an
 	// `unstructured.UnstructuredList` because we may have created multiple
 	// objects of different Kinds.
 	createdObjs := []*unstructured.Unstructured{}
 
-	objs, err := unstructuredFromReader(r)
+	// If the string is not a file path, consider it to be YAML/JSON content
+	// and marshal that into unstructured.Unstructured objects that we then
+	// pass to Create()
+	objs, err := manifestObjects(
+		ctx, "kube.create", a.Create, probablyFilePath(a.Create),
+	)
 	if err != nil {
-		rterr := fmt.Errorf("%w: %s", api.RuntimeError, err)
-		return rterr
+		return err
 	}
 	for _, obj := range objs {
 		gvk := obj.GetObjectKind().GroupVersionKind()
@@ -279,14 +426,14 @@ func (a *Action) create(
 			obj, err = c.client.Resource(res).Namespace(ons).Create(
 				ctx,
 				obj,
-				metav1.CreateOptions{},
+				a.createOptions(),
 			)
 		} else {
 			debug.Printf(ctx, "kube.create: %s (non-namespaced resource)", resName)
 			obj, err = c.client.Resource(res).Create(
 				ctx,
 				obj,
-				metav1.CreateOptions{},
+				a.createOptions(),
 			)
 		}
 		if err != nil {
@@ -306,35 +453,20 @@ func (a *Action) apply(
 	ns string,
 	out *interface{},
 ) error {
-	var err error
-	var r io.Reader
-	if probablyFilePath(a.Apply) {
-		path := a.Apply
-		f, err := os.Open(path)
-		if err != nil {
-			// This should never happen because we check during parse time
-			// whether the file can be opened.
-			rterr := fmt.Errorf("%w: %s", api.RuntimeError, err)
-			return rterr
-		}
-		defer f.Close()
-		r = f
-	} else {
-		// Consider the string to be YAML/JSON content and marshal that into an
-		// unstructured.Unstructured that we then pass to Apply()
-		r = strings.NewReader(a.Apply)
-	}
-
 	// This is what we return to the caller via the `out` param. It contains
 	// all of the applied objects. This is NOT an
 	// `unstructured.UnstructuredList` because we may have applied multiple
 	// objects of different Kinds.
 	appliedObjs := []*unstructured.Unstructured{}
 
-	objs, err := unstructuredFromReader(r)
+	// If the string is not a file path, consider it to be YAML/JSON content
+	// and marshal that into unstructured.Unstructured objects that we then
+	// pass to Apply()
+	objs, err := manifestObjects(
+		ctx, "kube.apply", a.Apply, probablyFilePath(a.Apply),
+	)
 	if err != nil {
-		rterr := fmt.Errorf("%w: %s", api.RuntimeError, err)
-		return rterr
+		return err
 	}
 	for _, obj := range objs {
 		gvk := obj.GetObjectKind().GroupVersionKind()
@@ -357,9 +489,7 @@ func (a *Action) apply(
 				// method...
 				obj.GetName(),
 				obj,
-				// TODO(jaypipes): Not sure if this hard-coded options struct is
-				// always going to work. Maybe add ability to control it?
-				metav1.ApplyOptions{FieldManager: fieldManagerName, Force: true},
+				a.applyOptions(),
 			)
 		} else {
 			debug.Printf(ctx, "kube.apply: %s (non-namespaced resource)", resName)
@@ -371,9 +501,7 @@ func (a *Action) apply(
 				// method...
 				obj.GetName(),
 				obj,
-				// TODO(jaypipes): Not sure if this hard-coded options struct is
-				// always going to work. Maybe add ability to control it?
-				metav1.ApplyOptions{FieldManager: fieldManagerName, Force: true},
+				a.applyOptions(),
 			)
 		}
 		if err != nil {
@@ -393,19 +521,11 @@ func (a *Action) delete(
 	ns string,
 ) error {
 	if a.Delete.FilePath() != "" {
-		path := a.Delete.FilePath()
-		f, err := os.Open(path)
-		if err != nil {
-			// This should never happen because we check during parse time
-			// whether the file can be opened.
-			rterr := fmt.Errorf("%w: %s", api.RuntimeError, err)
-			return rterr
-		}
-		defer f.Close()
-		objs, err := unstructuredFromReader(f)
+		objs, err := manifestObjects(
+			ctx, "kube.delete", a.Delete.FilePath(), true,
+		)
 		if err != nil {
-			rterr := fmt.Errorf("%w: %s", api.RuntimeError, err)
-			return rterr
+			return err
 		}
 		for _, obj := range objs {
 			gvk := obj.GetObjectKind().GroupVersionKind()
@@ -418,7 +538,7 @@ func (a *Action) delete(
 			if ons == "" {
 				ons = ns
 			}
-			if err = a.doDelete(ctx, c, res, name, ons); err != nil {
+			if err = a.doDelete(ctx, c, res, ons, name); err != nil {
 				return err
 			}
 		}
@@ -461,7 +581,7 @@ func (a *Action) doDelete(
 		return c.client.Resource(res).Namespace(ns).Delete(
 			ctx,
 			nameRep,
-			metav1.DeleteOptions{},
+			a.deleteOptions(),
 		)
 	}
 	debug.Printf(
@@ -471,7 +591,7 @@ func (a *Action) doDelete(
 	return c.client.Resource(res).Delete(
 		ctx,
 		nameRep,
-		metav1.DeleteOptions{},
+		a.deleteOptions(),
 	)
 }
 
@@ -500,7 +620,7 @@ func (a *Action) doDeleteCollection(
 		)
 		return c.client.Resource(res).Namespace(ns).DeleteCollection(
 			ctx,
-			metav1.DeleteOptions{},
+			a.deleteOptions(),
 			opts,
 		)
 	}
@@ -510,11 +630,206 @@ func (a *Action) doDeleteCollection(
 	)
 	return c.client.Resource(res).DeleteCollection(
 		ctx,
-		metav1.DeleteOptions{},
+		a.deleteOptions(),
 		opts,
 	)
 }
 
+// patch executes one or more Patch() calls against the Kubernetes API server,
+// returning any error returned from the client call and populating `out` with
+// the patched resource(s).
+func (a *Action) patch(
+	ctx context.Context,
+	c *connection,
+	ns string,
+	out *interface{},
+) error {
+	target := a.Patch.Target
+	arg := target.Arg
+	argRep := gdtcontext.ReplaceVariables(ctx, arg)
+	if arg != argRep {
+		debug.Printf(
+			ctx,
+			"kube.patch: replaced arg: %s -> %s",
+			arg, argRep,
+		)
+	}
+	res, err := c.gvrFromArg(argRep)
+	if err != nil {
+		return err
+	}
+	data, err := a.Patch.body(ctx)
+	if err != nil {
+		rterr := fmt.Errorf("%w: %s", api.RuntimeError, err)
+		return rterr
+	}
+	pt := a.Patch.patchType()
+	name := target.Name
+	if name == "" {
+		list, err := a.doList(ctx, c, res, ns, target.Labels)
+		if err != nil {
+			return err
+		}
+		patched := &unstructured.UnstructuredList{}
+		for _, item := range list.Items {
+			obj, err := a.doPatch(
+				ctx, c, res, item.GetNamespace(), item.GetName(), pt, data,
+			)
+			if err != nil {
+				return err
+			}
+			patched.Items = append(patched.Items, *obj)
+		}
+		*out = patched
+		return nil
+	}
+	nameRep := gdtcontext.ReplaceVariables(ctx, name)
+	if name != nameRep {
+		debug.Printf(
+			ctx,
+			"kube.patch: replaced name: %s -> %s",
+			name, nameRep,
+		)
+	}
+	obj, err := a.doPatch(ctx, c, res, ns, nameRep, pt, data)
+	if err == nil {
+		*out = obj
+	}
+	return err
+}
+
+// doPatch performs the Patch() call for a supplied resource kind and name
+func (a *Action) doPatch(
+	ctx context.Context,
+	c *connection,
+	res schema.GroupVersionResource,
+	ns string,
+	name string,
+	pt types.PatchType,
+	data []byte,
+) (*unstructured.Unstructured, error) {
+	resName := res.Resource
+	if c.resourceNamespaced(res) {
+		debug.Printf(
+			ctx, "kube.patch: %s/%s (ns: %s, type: %s)",
+			resName, name, ns, pt,
+		)
+		return c.client.Resource(res).Namespace(ns).Patch(
+			ctx,
+			name,
+			pt,
+			data,
+			a.patchOptions(),
+		)
+	}
+	debug.Printf(
+		ctx, "kube.patch: %s/%s (non-namespaced resource, type: %s)",
+		resName, name, pt,
+	)
+	return c.client.Resource(res).Patch(
+		ctx,
+		name,
+		pt,
+		data,
+		a.patchOptions(),
+	)
+}
+
+// manifestObjects returns the objects in the supplied manifest, which is either
+// a file path or inline YAML/JSON content, after replacing any variable
+// references in the manifest. `cmd` is used to prefix debug messages.
+func manifestObjects(
+	ctx context.Context,
+	cmd string,
+	manifest string,
+	isFile bool,
+) ([]*unstructured.Unstructured, error) {
+	content := manifest
+	if isFile {
+		b, err := os.ReadFile(manifest)
+		if err != nil {
+			// This should never happen because we check during parse time
+			// whether the file can be opened.
+			return nil, fmt.Errorf("%w: %s", api.RuntimeError, err)
+		}
+		content = string(b)
+	}
+	content = expandManifest(ctx, cmd, content, isFile)
+	objs, err := unstructuredFromReader(strings.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", api.RuntimeError, err)
+	}
+	return objs, nil
+}
+
+// expandManifest returns the supplied manifest content with variable
+// references replaced with the variable values from stored run data.
+//
+// Content read from a file has not been through the environment variable
+// expansion that the test scenario file itself goes through, so for file
+// content we first expand environment variables, with `$$` expanding to a
+// literal `$`, just like in the test scenario file.
+//
+// Inline content has already been through the test scenario file's
+// environment variable expansion. As in earlier versions, it is expanded a
+// second time, which lets an inline manifest write `$$FOO` to read the `FOO`
+// environment variable when the test spec runs. References to variables from
+// stored run data are left alone by this second pass.
+//
+// A dollar sign escaped with a backslash (`\$`) is never expanded and is
+// replaced with a literal `$` after all expansion is done. Note that inline
+// content has already been through environment variable expansion, so a
+// literal `$FOO` in inline content must be written as `\$$FOO`.
+func expandManifest(
+	ctx context.Context,
+	cmd string,
+	content string,
+	isFile bool,
+) string {
+	content = strings.ReplaceAll(content, `\$`, escapedDollarToken)
+	if isFile {
+		content = parse.ExpandWithFixedDoubleDollar(content)
+	} else {
+		content = expandInlineEnv(ctx, content)
+	}
+	unescape := func(s string) string {
+		return strings.ReplaceAll(s, escapedDollarToken, "$")
+	}
+	// We replace variables line by line so that we can log each replacement
+	lines := strings.Split(content, "\n")
+	for x, line := range lines {
+		lineRep := gdtcontext.ReplaceVariables(ctx, line)
+		if line != lineRep {
+			debug.Printf(
+				ctx,
+				"%s: replaced var: %s -> %s",
+				cmd,
+				strings.TrimSpace(unescape(line)),
+				strings.TrimSpace(unescape(lineRep)),
+			)
+		}
+		lines[x] = lineRep
+	}
+	return unescape(strings.Join(lines, "\n"))
+}
+
+// expandInlineEnv returns the supplied inline manifest content with
+// environment variables expanded, with `$$` expanding to a literal `$`.
+// References to variables from stored run data are kept as-is so that they
+// can be replaced with the variable values afterwards.
+func expandInlineEnv(ctx context.Context, content string) string {
+	vars := gdtcontext.PriorRun(ctx)
+	return os.Expand(content, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		if _, ok := vars[name]; ok {
+			return "$" + name
+		}
+		return os.Getenv(name)
+	})
+}
+
 // unstructuredFromReader attempts to read the supplied io.Reader and unmarshal
 // the content into zero or more unstructured.Unstructured objects
 func unstructuredFromReader(
@@ -531,11 +846,9 @@ func unstructuredFromReader(
 			}
 			return nil, err
 		}
-		data := parse.ExpandWithFixedDoubleDollar(string(raw))
-
 		obj := &unstructured.Unstructured{}
 		decoder := yaml.NewYAMLOrJSONDecoder(
-			bytes.NewBuffer([]byte(data)), len(data),
+			bytes.NewBuffer(raw), len(raw),
 		)
 		if err = decoder.Decode(obj); err != nil {
 			return nil, err
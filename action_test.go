@@ -0,0 +1,73 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"testing"
+
+	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandManifest(t *testing.T) {
+	t.Setenv("GDT_KUBE_GREETING", "hello")
+	t.Setenv("POD_IP", "from-env")
+
+	ctx := gdtcontext.SetRun(context.TODO(), map[string]any{
+		"POD_IP": "10.244.0.5",
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		isFile  bool
+		exp     string
+	}{
+		{
+			name:    "inline env var",
+			content: "greeting: $GDT_KUBE_GREETING",
+			exp:     "greeting: hello",
+		},
+		{
+			name:    "inline braced env var",
+			content: "greeting: ${GDT_KUBE_GREETING}!",
+			exp:     "greeting: hello!",
+		},
+		{
+			name:    "inline variable wins over env var",
+			content: "podIP: $POD_IP",
+			exp:     "podIP: 10.244.0.5",
+		},
+		{
+			name:    "inline escaped dollar",
+			content: `script: echo \$HOME`,
+			exp:     "script: echo $HOME",
+		},
+		{
+			name:    "inline double dollar",
+			content: "price: $$5",
+			exp:     "price: $5",
+		},
+		{
+			name:    "file env var and variable",
+			content: "greeting: $GDT_KUBE_GREETING\npodIP: $$POD_IP",
+			isFile:  true,
+			exp:     "greeting: hello\npodIP: 10.244.0.5",
+		},
+		{
+			name:    "file escaped dollar",
+			content: `script: echo \$HOME`,
+			isFile:  true,
+			exp:     "script: echo $HOME",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expandManifest(ctx, "kube.create", tt.content, tt.isFile)
+			assert.Equal(t, tt.exp, got)
+		})
+	}
+}
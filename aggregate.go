@@ -0,0 +1,133 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"fmt"
+
+	"github.com/samber/lo"
+	"github.com/theory/jsonpath"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// path returns the JSONPath expression of the aggregate.
+func (e *AggregateExpect) path() string {
+	if e.Count != "" {
+		return e.Count
+	}
+	return e.Sum
+}
+
+// String describes the aggregate in failures, e.g.
+// "count of $[?@.status.phase == 'Running']".
+func (e *AggregateExpect) String() string {
+	if e.Count != "" {
+		return "count of " + e.Count
+	}
+	return "sum of " + e.Sum
+}
+
+// aggregate returns the count or sum of the values the aggregate's JSONPath
+// expression selects from the supplied input.
+func (e *AggregateExpect) aggregate(input any) (resource.Quantity, error) {
+	p, err := jsonpath.Parse(e.path())
+	if err != nil {
+		// The JSONPath expression was validated at parse time.
+		return resource.Quantity{}, err
+	}
+	nodes := p.Select(input)
+	if e.Count != "" {
+		return *resource.NewQuantity(int64(len(nodes)), resource.DecimalSI), nil
+	}
+	sum := resource.Quantity{}
+	for _, node := range nodes {
+		q, ok := quantityOf(node)
+		if !ok {
+			return resource.Quantity{}, AggregateNotNumeric(e.Sum, node)
+		}
+		sum.Add(q)
+	}
+	return sum, nil
+}
+
+// inRange returns true if the supplied count or sum satisfies the
+// aggregate's Equals, Min and Max.
+func (e *AggregateExpect) inRange(got resource.Quantity) bool {
+	// The values were validated as numbers or resource quantities at parse
+	// time.
+	if e.Equals != "" {
+		exp, _ := quantityOf(e.Equals)
+		if got.Cmp(exp) != 0 {
+			return false
+		}
+	}
+	if e.Min != "" {
+		exp, _ := quantityOf(e.Min)
+		if got.Cmp(exp) < 0 {
+			return false
+		}
+	}
+	if e.Max != "" {
+		exp, _ := quantityOf(e.Max)
+		if got.Cmp(exp) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// expected describes the aggregate's Equals, Min and Max in failures, e.g.
+// "at least 3 and at most 5".
+func (e *AggregateExpect) expected() string {
+	if e.Equals != "" {
+		return e.Equals
+	}
+	return rangeString(e.Min, e.Max)
+}
+
+// rangeString describes a minimum and maximum in failures. Either may be
+// empty.
+func rangeString(minimum, maximum string) string {
+	switch {
+	case minimum != "" && maximum != "":
+		return fmt.Sprintf("at least %s and at most %s", minimum, maximum)
+	case minimum != "":
+		return "at least " + minimum
+	default:
+		return "at most " + maximum
+	}
+}
+
+// aggregatesOK returns true if the count or sum of every `assert.aggregate`
+// is in range.
+func (a *assertions) aggregatesOK() bool {
+	exp := a.exp
+	if len(exp.Aggregates) == 0 || !a.hasSubject() {
+		return true
+	}
+	objs, ok := a.subjectObjects()
+	if !ok {
+		a.Fail(AssertionUnsupported("aggregate", a.subjectDescription()))
+		return false
+	}
+	input := lo.Map(objs, func(obj *unstructured.Unstructured, _ int) any {
+		return obj.Object
+	})
+	res := true
+	for _, agg := range exp.Aggregates {
+		got, err := agg.aggregate(input)
+		if err != nil {
+			a.Fail(err)
+			res = false
+			continue
+		}
+		if !agg.inRange(got) {
+			a.Fail(AggregateNotInRange(agg.String(), agg.expected(), got.String()))
+			res = false
+		}
+	}
+	return res
+}
@@ -0,0 +1,119 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// testAggregateInput returns the items of a list of three Pods, two of which
+// are running.
+func testAggregateInput() []any {
+	pod := func(phase string, cpu any, restarts int64) any {
+		return map[string]any{
+			"kind": "Pod",
+			"spec": map[string]any{
+				"containers": []any{
+					map[string]any{
+						"resources": map[string]any{
+							"requests": map[string]any{"cpu": cpu},
+						},
+					},
+				},
+			},
+			"status": map[string]any{
+				"phase": phase,
+				"containerStatuses": []any{
+					map[string]any{"restartCount": restarts},
+				},
+			},
+		}
+	}
+	return []any{
+		pod("Running", "250m", 1),
+		pod("Running", "0.5", 0),
+		pod("Pending", int64(1), 2),
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		name string
+		agg  *AggregateExpect
+		exp  string
+		err  error
+	}{
+		{
+			name: "count with a filter",
+			agg:  &AggregateExpect{Count: "$[?@.status.phase == 'Running']"},
+			exp:  "2",
+		},
+		{
+			name: "count of nothing",
+			agg:  &AggregateExpect{Count: "$[?@.status.phase == 'Failed']"},
+			exp:  "0",
+		},
+		{
+			name: "sum of integers",
+			agg: &AggregateExpect{
+				Sum: "$[*].status.containerStatuses[*].restartCount",
+			},
+			exp: "3",
+		},
+		{
+			name: "sum of mixed resource quantities",
+			agg: &AggregateExpect{
+				Sum: "$[*].spec.containers[*].resources.requests.cpu",
+			},
+			exp: "1750m",
+		},
+		{
+			name: "sum of values that are not numbers",
+			agg:  &AggregateExpect{Sum: "$[*].status.phase"},
+			err:  ErrAggregateNotInRange,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.agg.aggregate(testAggregateInput())
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.exp, got.String())
+		})
+	}
+}
+
+func TestAggregateInRange(t *testing.T) {
+	tests := []struct {
+		name string
+		agg  *AggregateExpect
+		got  string
+		exp  bool
+	}{
+		{name: "equals", agg: &AggregateExpect{Equals: "2"}, got: "2", exp: true},
+		{name: "not equals", agg: &AggregateExpect{Equals: "2"}, got: "3", exp: false},
+		{name: "equals quantity", agg: &AggregateExpect{Equals: "0.5"}, got: "500m", exp: true},
+		{name: "min inclusive", agg: &AggregateExpect{Min: "2"}, got: "2", exp: true},
+		{name: "below min", agg: &AggregateExpect{Min: "2"}, got: "1", exp: false},
+		{name: "max inclusive", agg: &AggregateExpect{Max: "1500m"}, got: "1.5", exp: true},
+		{name: "above max", agg: &AggregateExpect{Max: "1500m"}, got: "1750m", exp: false},
+		{name: "in min and max", agg: &AggregateExpect{Min: "1", Max: "2"}, got: "1500m", exp: true},
+		{name: "outside min and max", agg: &AggregateExpect{Min: "1", Max: "2"}, got: "3", exp: false},
+		{name: "binary suffixes", agg: &AggregateExpect{Max: "1Gi"}, got: "1024Mi", exp: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resource.MustParse(tt.got)
+			assert.Equal(t, tt.exp, tt.agg.inRange(got))
+		})
+	}
+}
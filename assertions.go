@@ -15,6 +15,7 @@ import (
 
 	"github.com/gdt-dev/core/api"
 	gdtjson "github.com/gdt-dev/core/assertion/json"
+	"github.com/samber/lo"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -33,6 +34,10 @@ type Expect struct {
 	// the response when the Get request was translated into a List operation
 	// (i.e. when the resource specified was a plural kind
 	Len *int `yaml:"len,omitempty"`
+	// LenRange contains the minimum and maximum number of items in the
+	// response. It is set when `len` is an object with `min` and/or `max`
+	// fields instead of an integer.
+	LenRange *LenRange `yaml:"-"`
 	// NotFound is a bool indicating the result of a call should be a
 	// NotFound error. Alternately, the user can set `assert.len = 0` and for
 	// single-object-returning calls (e.g. `get` or `delete`) the assertion is
@@ -102,6 +107,29 @@ type Expect struct {
 	//          status:
 	//            readyReplicas: 2
 	// ```
+	//
+	// When the test spec is a `kube.create` or `kube.apply` of a manifest
+	// containing more than one object, the map must identify the object to
+	// match by its `kind` and/or `metadata.name` fields. Alternately, you may
+	// supply a list of maps (or YAML strings or file paths) that are matched
+	// by index against the objects in the order they appear in the manifest.
+	//
+	// When the test spec returns a list of objects, e.g. a `kube.get` of a
+	// plural kind, a map (or YAML string or file path) must be matched by
+	// every item in the list. Alternately, you may supply a `ListMatches`
+	// object with `any`, `all`, `none` or `exactly` fields:
+	//
+	// ```yaml
+	// tests:
+	//  - name: check at least one pod is ready
+	//    kube:
+	//      get: pods
+	//      assert:
+	//        matches:
+	//          any:
+	//            status:
+	//              phase: Running
+	// ```
 	Matches any `yaml:"matches,omitempty"`
 	// JSON contains the assertions about JSON data in a response from the
 	// Kubernetes API server.
@@ -161,8 +189,331 @@ type Expect struct {
 	//            reason: NewReplicaSetAvailable
 	// ```
 	Conditions map[string]*ConditionMatch `yaml:"conditions,omitempty"`
+	// ConditionsAny contains assertions about the `Status.Conditions` of the
+	// objects in a list, like Conditions, that at least one of the objects
+	// must satisfy. It is set from the `any` field of `conditions`, while the
+	// `all` field sets Conditions, which every object must satisfy.
+	//
+	// ```yaml
+	// tests:
+	//  - kube:
+	//      get:
+	//        type: pods
+	//        labels:
+	//          app: nginx
+	//    assert:
+	//      conditions:
+	//        any:
+	//          ready: true
+	// ```
+	ConditionsAny map[string]*ConditionMatch `yaml:"-"`
+	// Ready contains the status that the returned objects are expected to
+	// have, as computed from their kind's readiness rules: Current,
+	// InProgress, Failed or Terminating. `ready: true` is shorthand for
+	// `ready: Current` and `ready: false` expects any status other than
+	// Current.
+	//
+	// ```yaml
+	// tests:
+	//  - kube:
+	//      get: deployments/nginx
+	//    assert:
+	//      ready: true
+	// ```
+	Ready *ReadyExpect `yaml:"ready,omitempty"`
 	// Placement describes expected Pod scheduling spread or pack outcomes.
 	Placement *PlacementAssertion `yaml:"placement,omitempty"`
+	// ExitCode is the expected exit code for the command executed by
+	// `kube.exec`. The default (0) is the universal successful exit code, so
+	// you only need to set this if you expect a non-successful result from
+	// executing the command.
+	ExitCode int `yaml:"exit-code,omitempty"`
+	// Out has things that are expected in the stdout of the command executed
+	// by `kube.exec`.
+	Out *PipeExpect `yaml:"out,omitempty"`
+	// Err has things that are expected in the stderr of the command executed
+	// by `kube.exec`.
+	Err *PipeExpect `yaml:"err,omitempty"`
+	// Logs has things that are expected in the container logs fetched by
+	// `kube.logs`.
+	Logs *PipeExpect `yaml:"logs,omitempty"`
+	// Watch contains the assertions to make about the events recorded by
+	// `kube.watch`.
+	Watch *WatchExpect `yaml:"watch,omitempty"`
+	// Events contains the assertions to make about the Events fetched by
+	// `kube.events`.
+	Events *EventsExpect `yaml:"events,omitempty"`
+	// Status contains the assertions to make about the Status returned by the
+	// Kubernetes API server when it rejects a request, e.g. an admission
+	// webhook or CEL validation rule denying a `kube.create`. When Status is
+	// set, the request is expected to fail.
+	//
+	// ```yaml
+	// tests:
+	//  - kube:
+	//      create: testdata/manifests/bad-replicas.yaml
+	//    assert:
+	//      status:
+	//        code: 422
+	//        reason: Invalid
+	//        causes:
+	//          - field: spec.replicas
+	//            message: must be less than or equal to 10
+	// ```
+	Status *StatusExpect `yaml:"status,omitempty"`
+	// Conflict contains the assertions to make about the field manager
+	// conflict returned by the Kubernetes API server when a `kube.apply` with
+	// `force: false` tries to change fields owned by another field manager.
+	//
+	// ```yaml
+	// tests:
+	//  - kube:
+	//      apply: testdata/manifests/deployment-replicas.yaml
+	//      field-manager: my-user
+	//      force: false
+	//    assert:
+	//      conflict:
+	//        manager: my-controller
+	//        fields: .spec.replicas
+	// ```
+	Conflict *ConflictExpect `yaml:"conflict,omitempty"`
+	// ManagedFields is a map, keyed by field path (e.g. `.spec.replicas`), of
+	// the name of the field manager that is expected to own that field
+	// according to the object's `metadata.managedFields`.
+	ManagedFields map[string]string `yaml:"managed-fields,omitempty"`
+	// Warnings contains the assertions to make about the warnings returned
+	// in `Warning` response headers by the Kubernetes API server, e.g. for a
+	// deprecated apiVersion or from Pod Security Admission in `warn` mode.
+	//
+	// ```yaml
+	// tests:
+	//  - kube:
+	//      create: testdata/manifests/privileged-pod.yaml
+	//    assert:
+	//      warnings:
+	//        contains: would violate PodSecurity "restricted:latest"
+	// ```
+	Warnings *WarningsExpect `yaml:"warnings,omitempty"`
+	// Aggregates contains assertions about the count or sum of the values
+	// selected by a JSONPath expression evaluated against the array of
+	// returned objects.
+	//
+	// ```yaml
+	// tests:
+	//  - kube:
+	//      get:
+	//        type: pods
+	//        labels:
+	//          app: nginx
+	//    assert:
+	//      aggregate:
+	//        - count: $[?@.status.phase == 'Running']
+	//          min: 3
+	// ```
+	Aggregates []*AggregateExpect `yaml:"aggregate,omitempty"`
+	// CEL contains one or more CEL expressions that must all evaluate to
+	// true. `object` is bound to the returned object (or the list object
+	// when a list was returned), `items` to the returned objects and `vars`
+	// to the scenario's variables.
+	//
+	// ```yaml
+	// tests:
+	//  - kube:
+	//      get: deployments/nginx
+	//    assert:
+	//      cel:
+	//        - object.status.readyReplicas == object.spec.replicas
+	//        - object.spec.template.spec.containers.all(c, has(c.resources.limits))
+	// ```
+	CEL *api.FlexStrings `yaml:"cel,omitempty"`
+	// Snapshot compares the returned objects with the objects in a golden
+	// YAML file, after removing fields that change every time an object is
+	// created or updated, like `metadata.uid` and the timestamps in
+	// `status`. When the UpdateSnapshots variable or the
+	// `GDT_KUBE_UPDATE_SNAPSHOTS` environment variable is set, the golden file
+	// is written instead.
+	//
+	// ```yaml
+	// tests:
+	//  - kube:
+	//      get: deployments/nginx
+	//    assert:
+	//      snapshot:
+	//        path: snapshots/nginx.yaml
+	//        ignore:
+	//          - $.metadata.annotations
+	// ```
+	Snapshot *SnapshotExpect `yaml:"snapshot,omitempty"`
+}
+
+// SnapshotExpect contains the path to a golden file and the fields of the
+// returned objects that are not compared with it.
+type SnapshotExpect struct {
+	// Path is the path to the golden file, relative to the scenario file.
+	Path string `yaml:"path"`
+	// Ignore contains JSONPath expressions, evaluated against each returned
+	// object, selecting fields that are removed before comparing.
+	Ignore []string `yaml:"ignore,omitempty"`
+}
+
+// LenRange contains the minimum and maximum number of items expected in a
+// response.
+type LenRange struct {
+	// Min is the minimum number of items, if any.
+	Min *int `yaml:"min,omitempty"`
+	// Max is the maximum number of items, if any.
+	Max *int `yaml:"max,omitempty"`
+}
+
+// AggregateExpect contains an assertion about the count or the sum of the
+// values selected by a JSONPath expression. One of Count or Sum is set, along
+// with one or more of Equals, Min and Max, which are numbers or resource
+// quantities, e.g. `3` or `1500m`.
+type AggregateExpect struct {
+	// Count is a JSONPath expression. The number of values it selects is
+	// compared.
+	Count string `yaml:"count,omitempty"`
+	// Sum is a JSONPath expression. The sum of the numbers or resource
+	// quantities it selects is compared.
+	Sum string `yaml:"sum,omitempty"`
+	// Equals is the value the count or sum must equal.
+	Equals string `yaml:"equals,omitempty"`
+	// Min is the minimum value of the count or sum.
+	Min string `yaml:"min,omitempty"`
+	// Max is the maximum value of the count or sum.
+	Max string `yaml:"max,omitempty"`
+}
+
+// ReadyExpect contains the computed status that objects are expected to
+// have, or not have.
+type ReadyExpect struct {
+	// Status is one of Current, InProgress, Failed or Terminating.
+	Status string `yaml:"-"`
+	// Not indicates that the objects must not have Status.
+	Not bool `yaml:"-"`
+}
+
+// String returns the expected status as it is described in failures, e.g.
+// "Current" or "not Current".
+func (e *ReadyExpect) String() string {
+	if e.Not {
+		return "not " + e.Status
+	}
+	return e.Status
+}
+
+// WarningsExpect contains assertions about the warnings returned by the
+// Kubernetes API server
+type WarningsExpect struct {
+	// Contains is one or more strings that *all* must be contained in a
+	// warning
+	Contains *api.FlexStrings `yaml:"contains,omitempty"`
+	// None asserts that the Kubernetes API server returned no warnings
+	None bool `yaml:"none,omitempty"`
+	// Count is the expected number of unique warnings
+	Count *int `yaml:"count,omitempty"`
+}
+
+// ListMatches contains assertions about the items of a list of objects
+type ListMatches struct {
+	// Any is a `matches` fragment that at least one item must match
+	Any any `yaml:"any,omitempty"`
+	// All is a `matches` fragment that every item must match
+	All any `yaml:"all,omitempty"`
+	// None is a `matches` fragment that no item may match
+	None any `yaml:"none,omitempty"`
+	// Exactly is a list of `matches` fragments, each identifying an item by
+	// its `metadata.name`. The list must contain exactly the identified
+	// items, in any order, and each item must match its fragment.
+	Exactly []any `yaml:"exactly,omitempty"`
+}
+
+// StatusExpect contains assertions about the Status returned by the
+// Kubernetes API server when it rejects a request
+type StatusExpect struct {
+	// Code is the expected HTTP status code, e.g. 403, 409 or 422
+	Code int `yaml:"code,omitempty"`
+	// Reason is the expected StatusReason, e.g. "Forbidden",
+	// "AlreadyExists", "Invalid" or "Conflict", matched case-insensitively
+	Reason string `yaml:"reason,omitempty"`
+	// Message is a string that must be contained in the Status message
+	Message string `yaml:"message,omitempty"`
+	// Causes contains the causes that *all* must be found in the Status
+	// details
+	Causes []*StatusCauseExpect `yaml:"causes,omitempty"`
+}
+
+// StatusCauseExpect describes a cause that must be found in the details of a
+// Status returned by the Kubernetes API server. Each field that is set must
+// match the same cause.
+type StatusCauseExpect struct {
+	// Field is the field path of the cause, e.g. "spec.replicas". A leading
+	// `.` is ignored.
+	Field string `yaml:"field,omitempty"`
+	// Type is the CauseType of the cause, e.g. "FieldValueInvalid"
+	Type string `yaml:"type,omitempty"`
+	// Message is a string that must be contained in the cause's message
+	Message string `yaml:"message,omitempty"`
+}
+
+// ConflictExpect contains assertions about the field manager conflict returned
+// by a server-side apply
+type ConflictExpect struct {
+	// Manager is the name of the field manager that the conflict must name
+	Manager string `yaml:"manager,omitempty"`
+	// Fields is one or more field paths, e.g. ".spec.replicas", that *all*
+	// must be named in the conflict
+	Fields *api.FlexStrings `yaml:"fields,omitempty"`
+}
+
+// EventsExpect contains assertions about the Events fetched by `kube.events`
+type EventsExpect struct {
+	// HasReason is one or more Event reasons, e.g. "FailedScheduling", that
+	// *all* must be present in the fetched Events
+	HasReason *api.FlexStrings `yaml:"has-reason,omitempty"`
+	// NoWarnings asserts that none of the fetched Events are of type Warning
+	NoWarnings bool `yaml:"no-warnings,omitempty"`
+	// Type is the Event type ("Normal" or "Warning") that Events must have to
+	// be considered by the HasReason and Count assertions
+	Type string `yaml:"type,omitempty"`
+	// Count is the minimum number of times the considered Events must have
+	// occurred, summing each Event's count of occurrences
+	Count *int `yaml:"count,omitempty"`
+}
+
+// WatchExpect contains assertions about the events recorded by `kube.watch`
+type WatchExpect struct {
+	// Sequence is an ordered list of `matches` fragments. Each fragment must
+	// match an event's object, in order, though other events may come between
+	// the matching events.
+	Sequence []any `yaml:"sequence,omitempty"`
+	// Never is a list of `matches` fragments that no event's object may
+	// match.
+	Never []any `yaml:"never,omitempty"`
+	// Added is the expected number of ADDED events
+	Added *int `yaml:"added,omitempty"`
+	// Modified is the expected number of MODIFIED events
+	Modified *int `yaml:"modified,omitempty"`
+	// Deleted is the expected number of DELETED events
+	Deleted *int `yaml:"deleted,omitempty"`
+}
+
+// PipeExpect contains assertions about the contents of a pipe
+type PipeExpect struct {
+	// ContainsAll is one or more strings that *all* must be present in the
+	// contents of the pipe
+	ContainsAll *api.FlexStrings `yaml:"contains,omitempty"`
+	// ContainsNone is one or more strings, *none of which* should be present
+	// in the contents of the pipe
+	ContainsNone *api.FlexStrings `yaml:"contains-none-of,omitempty"`
+	// ContainsAny is one or more strings of which *at least one* must be
+	// present in the contents of the pipe
+	ContainsAny *api.FlexStrings `yaml:"contains-one-of,omitempty"`
+	// Regex is one or more regular expressions that *all* must match the
+	// contents of the pipe
+	Regex *api.FlexStrings `yaml:"regex,omitempty"`
+	// Lines is the number of lines expected in the contents of the pipe
+	Lines *int `yaml:"lines,omitempty"`
 }
 
 // conditionMatch is a struct with fields that we will match a resource's
@@ -170,12 +521,35 @@ type Expect struct {
 type conditionMatch struct {
 	Status *api.FlexStrings `yaml:"status,omitempty"`
 	Reason string           `yaml:"reason,omitempty"`
+	// ReasonContains is a string the Condition's reason must contain.
+	ReasonContains string `yaml:"reason-contains,omitempty"`
+	// ReasonRegex is a regular expression the Condition's reason must
+	// match.
+	ReasonRegex string `yaml:"reason-regex,omitempty"`
+	// Message is the exact message the Condition must have.
+	Message string `yaml:"message,omitempty"`
+	// MessageContains is a string the Condition's message must contain.
+	MessageContains string `yaml:"message-contains,omitempty"`
+	// MessageRegex is a regular expression the Condition's message must
+	// match.
+	MessageRegex string `yaml:"message-regex,omitempty"`
+	// ObservedGeneration, when `current`, requires the Condition's
+	// `observedGeneration` to equal the resource's `metadata.generation`, so
+	// that a stale Condition is not accepted.
+	ObservedGeneration string `yaml:"observed-generation,omitempty"`
+	// StableFor is a duration, e.g. `30s`, that must have passed since the
+	// Condition's `lastTransitionTime`.
+	StableFor string `yaml:"stable-for,omitempty"`
 }
 
+// ObservedGenerationCurrent is the only valid value of a ConditionMatch's
+// `observed-generation` field.
+const ObservedGenerationCurrent = "current"
+
 // ConditionMatch can be a string (the ConditionStatus to match), a slice of
 // strings (any of the ConditionStatus values to match) or an object with
-// Status and Reason fields describing the Condition fields we want to match
-// on.
+// Status, Reason, Message, ObservedGeneration and StableFor fields
+// describing the Condition fields we want to match on.
 type ConditionMatch struct {
 	conditionMatch
 }
@@ -188,6 +562,52 @@ type PlacementAssertion struct {
 	// Pack contains zero or more topology keys that gdt-kube will assert
 	// bin-packing of resources within.
 	Pack *api.FlexStrings `yaml:"pack,omitempty"`
+	// MaxSkew is the maximum difference between the number of Pods in the
+	// domain of a Spread topology key with the most Pods and the domain with
+	// the fewest Pods. If nil, 1 is used.
+	MaxSkew *int `yaml:"max-skew,omitempty"`
+	// MinDomains is the minimum number of domains of a Spread topology key.
+	// As with a Pod's `topologySpreadConstraints`, when the Pods can be
+	// scheduled to fewer domains than this, the fewest Pods in a domain is
+	// considered to be zero.
+	MinDomains int `yaml:"min-domains,omitempty"`
+	// NodeSelector is a map of node labels that the nodes every Pod landed
+	// on must have.
+	NodeSelector map[string]string `yaml:"node-selector,omitempty"`
+	// AvoidTaints contains zero or more taints, in the `key[=value][:effect]`
+	// format of `kubectl taint`, that the nodes the Pods landed on must not
+	// have.
+	AvoidTaints *api.FlexStrings `yaml:"avoid-taints,omitempty"`
+	// Affinity describes the Pods of another workload that every Pod must be
+	// co-located with.
+	Affinity *PlacementAffinity `yaml:"affinity,omitempty"`
+	// AntiAffinity describes the Pods of another workload that every Pod must
+	// be kept away from.
+	AntiAffinity *PlacementAffinity `yaml:"anti-affinity,omitempty"`
+	// MaxPodsPerNode is the maximum number of Pods that may land on any
+	// single node.
+	MaxPodsPerNode *int `yaml:"max-pods-per-node,omitempty"`
+}
+
+// PlacementAffinity describes the Pods that the Pods of the subject must be
+// co-located with or kept away from.
+type PlacementAffinity struct {
+	// Target identifies the Pods to be co-located with or kept away from. It
+	// takes the same forms as the `kube.exec` target: a Pod name, a workload
+	// (e.g. `deployments/cache`) or a label selector.
+	Target *ResourceIdentifier `yaml:"target"`
+	// TopologyKey is the node label whose value is the domain that Pods are
+	// co-located in. If empty, `kubernetes.io/hostname` is used, meaning the
+	// Pods must be on the same node.
+	TopologyKey string `yaml:"topology-key,omitempty"`
+}
+
+// maxSkew returns the maximum skew of the Spread assertion
+func (p *PlacementAssertion) maxSkew() int {
+	if p.MaxSkew != nil {
+		return *p.MaxSkew
+	}
+	return 1
 }
 
 // assertions contains all assertions made for the exec test
@@ -204,8 +624,17 @@ type assertions struct {
 	err error
 	// r is either an `unstructured.Unstructured` or an
 	// `unstructured.UnstructuredList` response returned from the kube client
-	// call.
+	// call, the `[]*unstructured.Unstructured` objects created or applied by
+	// `kube.create` or `kube.apply`, or the output of a `kube.exec`,
+	// `kube.logs`, `kube.describe`, `kube.wait`, `kube.watch` or
+	// `kube.events` action.
 	r any
+	// warnings contains the warnings returned by the Kubernetes API server
+	// while executing the action.
+	warnings []string
+	// failOnDeprecation indicates that any deprecation warning returned by
+	// the Kubernetes API server should fail the assertions.
+	failOnDeprecation bool
 }
 
 // Fail appends a supplied error to the set of failed assertions
@@ -230,26 +659,65 @@ func (a *assertions) OK(ctx context.Context) bool {
 			a.Fail(api.UnexpectedError(a.err))
 			return false
 		}
-		return true
+		// A command executed with `kube.exec` is expected to succeed unless
+		// the test author says otherwise.
+		if out, ok := a.r.(*execOutput); ok && out.exitCode != 0 {
+			a.Fail(api.NotEqual(0, out.exitCode))
+			return false
+		}
+		return a.waitOK() && a.watchOK(ctx) && a.warningsOK(ctx)
 	}
 	if !a.errorOK() {
 		return false
 	}
+	if !a.waitOK() {
+		return false
+	}
 	if !a.lenOK() {
 		return false
 	}
 	if !a.matchesOK(ctx) {
 		return false
 	}
-	if !a.conditionsOK() {
+	if !a.conditionsOK(ctx) {
+		return false
+	}
+	if !a.readyOK() {
 		return false
 	}
 	if !a.jsonOK(ctx) {
 		return false
 	}
+	if !a.celOK(ctx) {
+		return false
+	}
+	if !a.aggregatesOK() {
+		return false
+	}
+	if !a.snapshotOK(ctx) {
+		return false
+	}
 	if !a.placementOK(ctx) {
 		return false
 	}
+	if !a.execOK(ctx) {
+		return false
+	}
+	if !a.logsOK(ctx) {
+		return false
+	}
+	if !a.watchOK(ctx) {
+		return false
+	}
+	if !a.eventsOK(ctx) {
+		return false
+	}
+	if !a.managedFieldsOK() {
+		return false
+	}
+	if !a.warningsOK(ctx) {
+		return false
+	}
 	return true
 }
 
@@ -257,6 +725,7 @@ func (a *assertions) OK(ctx context.Context) bool {
 // false otherwise.
 func (a *assertions) errorOK() bool {
 	exp := a.exp
+	statusMatched := false
 	// We first evaluate whether an error we have received should be
 	// "swallowed" because it was expected. If we still have an error after
 	// swallowing all unexpected errors, then that is an unexpected error and
@@ -274,7 +743,21 @@ func (a *assertions) errorOK() bool {
 		// that has a 404 ErrStatus.Code in it
 		apierr, ok := a.err.(*apierrors.StatusError)
 		if ok {
-			if a.expectsNotFound() {
+			if exp.Status != nil || exp.Conflict != nil {
+				pass := true
+				if exp.Status != nil {
+					pass = a.statusOK(apierr)
+				}
+				if exp.Conflict != nil {
+					pass = a.conflictOK(apierr) && pass
+				}
+				if !pass {
+					return false
+				}
+				// "Swallow" the error since we expected it.
+				a.err = nil
+				statusMatched = true
+			} else if a.expectsNotFound() {
 				if http.StatusNotFound != int(apierr.ErrStatus.Code) {
 					msg := fmt.Sprintf("got status code %d", apierr.ErrStatus.Code)
 					a.Fail(ExpectedNotFound(msg))
@@ -288,6 +771,14 @@ func (a *assertions) errorOK() bool {
 			}
 		}
 	}
+	if exp.Status != nil && !statusMatched {
+		a.Fail(ExpectedStatus("request succeeded"))
+		return false
+	}
+	if exp.Conflict != nil && !statusMatched {
+		a.Fail(ExpectedConflict("request succeeded"))
+		return false
+	}
 	if exp.Error != "" && a.r != nil {
 		if a.err == nil {
 			a.Fail(api.UnexpectedError(a.err))
@@ -313,85 +804,183 @@ func (a *assertions) expectsNotFound() bool {
 // lenOK returns true if the subject matches the Len condition, false otherwise
 func (a *assertions) lenOK() bool {
 	exp := a.exp
-	if exp.Len != nil && a.hasSubject() {
-		// if the supplied resp is a list of objects returned by the dynamic
-		// client check its length
-		list, ok := a.r.(*unstructured.UnstructuredList)
-		if ok && list != nil {
-			if len(list.Items) != *exp.Len {
-				a.Fail(api.NotEqualLength(*exp.Len, len(list.Items)))
-				return false
-			}
+	if (exp.Len == nil && exp.LenRange == nil) || !a.hasSubject() {
+		return true
+	}
+	got, ok := a.subjectLen()
+	if !ok {
+		return true
+	}
+	if exp.Len != nil && got != *exp.Len {
+		a.Fail(api.NotEqualLength(*exp.Len, got))
+		return false
+	}
+	if lr := exp.LenRange; lr != nil {
+		if (lr.Min != nil && got < *lr.Min) || (lr.Max != nil && got > *lr.Max) {
+			a.Fail(LenNotInRange(lr.Min, lr.Max, got))
+			return false
 		}
 	}
 	return true
 }
 
+// subjectLen returns the number of items in the subject and true, or false
+// if the subject is not a list of items, e.g. a single object.
+func (a *assertions) subjectLen() (int, bool) {
+	switch r := a.r.(type) {
+	case *unstructured.UnstructuredList:
+		if r != nil {
+			return len(r.Items), true
+		}
+	case *waitOutput:
+		if r != nil {
+			return len(r.objects), true
+		}
+	case []*unstructured.Unstructured:
+		if r != nil {
+			return len(r), true
+		}
+	case *eventsOutput:
+		if r != nil {
+			return len(r.events), true
+		}
+	}
+	return 0, false
+}
+
 // matchesOK returns true if the subject matches the Matches condition, false
 // otherwise
 func (a *assertions) matchesOK(ctx context.Context) bool {
 	exp := a.exp
 	if exp.Matches != nil && a.hasSubject() {
-		matchObj := matchObjectFromAny(ctx, exp.Matches)
+		if lm, isListMatches := exp.Matches.(*ListMatches); isListMatches {
+			objs, ok := a.subjectObjects()
+			if !ok {
+				a.Fail(AssertionUnsupported("matches", a.subjectDescription()))
+				return false
+			}
+			return a.listMatchesOK(ctx, objs, lm)
+		}
 		res, ok := a.r.(*unstructured.Unstructured)
+		if d, isDescribe := a.r.(*describeOutput); isDescribe {
+			// The composite `kube.describe` document is matched as a whole so
+			// that the object and its events, children and pods can be
+			// matched together.
+			res, ok = &unstructured.Unstructured{Object: d.asMap()}, true
+		}
+		if objs, isObjs := a.r.([]*unstructured.Unstructured); isObjs {
+			return a.objectsMatchesOK(ctx, objs)
+		}
+		if wo, isWait := a.r.(*waitOutput); isWait {
+			// The objects waited on by `kube.wait` are matched just like the
+			// objects created by `kube.create` or applied by `kube.apply`.
+			return a.objectsMatchesOK(ctx, wo.objects)
+		}
+		if _, isList := exp.Matches.([]any); isList {
+			a.Fail(MatchesObjectNotIdentified(
+				"a list of matches is only supported for the objects " +
+					"created by kube.create, applied by kube.apply or " +
+					"waited on by kube.wait",
+			))
+			return false
+		}
 		if ok {
+			matchObj := matchObjectFromAny(ctx, exp.Matches)
 			delta := compareResourceToMatchObject(res, matchObj)
 			if !delta.Empty() {
 				for _, diff := range delta.Differences() {
 					a.Fail(MatchesNotEqual(diff))
 				}
+				expected, actual := matchesDiffViews(res, matchObj)
+				a.failDiff(
+					ctx, ErrMatchesNotEqual, "matches", objectTitle(res),
+					expected, actual,
+				)
 				return false
 			}
 			return true
 		}
-
-		// TODO(jaypipes): if the supplied resp is a list of objects returned
-		// by the dynamic client check each against the supplied matches
-		// fields.
-		//list, ok := a.r.(*unstructured.UnstructuredList)
-		//if ok {
-		//	for _, obj := range list.Items {
-		//      diff := compareResourceToMatchObject(obj, matchObj)
-		//
-		//		a.Fail(api.NotEqualLength(*exp.Len, len(list.Items)))
-		//		return false
-		//	}
-		//}
+		if _, isList := a.r.(*unstructured.UnstructuredList); isList {
+			// A single matches fragment must be matched by every item of a
+			// list of objects.
+			objs, _ := a.subjectObjects()
+			return a.listMatchesOK(ctx, objs, &ListMatches{All: exp.Matches})
+		}
+		a.Fail(AssertionUnsupported("matches", a.subjectDescription()))
+		return false
 	}
 	return true
 }
 
 // conditionsOK returns true if the subject matches the Conditions condition,
 // false otherwise
-func (a *assertions) conditionsOK() bool {
+func (a *assertions) conditionsOK(ctx context.Context) bool {
 	exp := a.exp
-	if exp.Conditions != nil && a.hasSubject() {
-		res, ok := a.r.(*unstructured.Unstructured)
-		if ok {
-			delta := compareConditions(res, exp.Conditions)
-			if !delta.Empty() {
-				for _, diff := range delta.Differences() {
-					a.Fail(ConditionDoesNotMatch(diff))
+	if (exp.Conditions == nil && exp.ConditionsAny == nil) || !a.hasSubject() {
+		return true
+	}
+	objs, ok := a.subjectObjects()
+	if !ok {
+		a.Fail(AssertionUnsupported("conditions", a.subjectDescription()))
+		return false
+	}
+	_, single := a.r.(*unstructured.Unstructured)
+	if _, isDescribe := a.r.(*describeOutput); isDescribe {
+		single = true
+	}
+	// When there is more than one object, e.g. the objects created by
+	// `kube.create` or the items of a list, the conditions must hold for
+	// each object.
+	pass := true
+	if exp.Conditions != nil {
+		for _, obj := range objs {
+			delta := compareConditions(obj, exp.Conditions)
+			for _, diff := range delta.Differences() {
+				if !single {
+					diff = fmt.Sprintf("%s: %s", objectTitle(obj), diff)
 				}
-				return false
+				a.Fail(ConditionDoesNotMatch(diff))
+				pass = false
+			}
+			if !delta.Empty() {
+				expected, actual := conditionsDiffViews(obj, exp.Conditions)
+				a.failDiff(
+					ctx, ErrConditionDoesNotMatch, "conditions",
+					objectTitle(obj), expected, actual,
+				)
 			}
-			return true
 		}
-
-		// TODO(jaypipes): if the supplied resp is a list of objects returned
-		// by the dynamic client check each against the supplied matches
-		// fields.
-		//list, ok := a.r.(*unstructured.UnstructuredList)
-		//if ok {
-		//	for _, obj := range list.Items {
-		//      diff := compareResourceToMatchObject(obj, matchObj)
-		//
-		//		a.Fail(api.NotEqualLength(*exp.Len, len(list.Items)))
-		//		return false
-		//	}
-		//}
 	}
-	return true
+	if exp.ConditionsAny != nil {
+		itemDiffs := []string{}
+		// The diff is shown for the item with the fewest differences, which
+		// is most likely the item the test author expected to match.
+		var closest *unstructured.Unstructured
+		fewest := 0
+		for _, obj := range objs {
+			delta := compareConditions(obj, exp.ConditionsAny)
+			if delta.Empty() {
+				return pass
+			}
+			diffs := delta.Differences()
+			if closest == nil || len(diffs) < fewest {
+				closest, fewest = obj, len(diffs)
+			}
+			itemDiffs = append(itemDiffs, fmt.Sprintf(
+				"%s: %s", objectTitle(obj), strings.Join(diffs, ", "),
+			))
+		}
+		a.Fail(ConditionsNoItem(len(objs), itemDiffs))
+		pass = false
+		if closest != nil {
+			expected, actual := conditionsDiffViews(closest, exp.ConditionsAny)
+			a.failDiff(
+				ctx, ErrConditionsNoItem, "conditions", objectTitle(closest),
+				expected, actual,
+			)
+		}
+	}
+	return pass
 }
 
 // jsonOK returns true if the subject matches the JSON conditions, false
@@ -424,6 +1013,46 @@ func (a *assertions) jsonOK(ctx context.Context) bool {
 				)
 				return false
 			}
+		case []*unstructured.Unstructured:
+			res := a.r.([]*unstructured.Unstructured)
+			if b, err = json.Marshal(objectPtrsAsSlice(res)); err != nil {
+				fmt.Fprintf(
+					os.Stderr, "unable to marshal []any: %s\n", err,
+				)
+				return false
+			}
+		case *waitOutput:
+			res := a.r.(*waitOutput)
+			if b, err = json.Marshal(res.items()); err != nil {
+				fmt.Fprintf(
+					os.Stderr, "unable to marshal []any: %s\n", err,
+				)
+				return false
+			}
+		case *watchOutput:
+			res := a.r.(*watchOutput)
+			if b, err = json.Marshal(res.items()); err != nil {
+				fmt.Fprintf(
+					os.Stderr, "unable to marshal []any: %s\n", err,
+				)
+				return false
+			}
+		case *eventsOutput:
+			res := a.r.(*eventsOutput)
+			if b, err = json.Marshal(res.items()); err != nil {
+				fmt.Fprintf(
+					os.Stderr, "unable to marshal []any: %s\n", err,
+				)
+				return false
+			}
+		case *describeOutput:
+			res := a.r.(*describeOutput)
+			if b, err = json.Marshal(res.asMap()); err != nil {
+				fmt.Fprintf(
+					os.Stderr, "unable to marshal describe output: %s\n", err,
+				)
+				return false
+			}
 		default:
 			fmt.Fprintf(
 				os.Stderr, "unsupported type %T for JSON assertion\n", a.r,
@@ -436,6 +1065,13 @@ func (a *assertions) jsonOK(ctx context.Context) bool {
 			for _, f := range ja.Failures() {
 				a.Fail(f)
 			}
+			if len(exp.JSON.Paths) > 0 {
+				expected, actual := jsonDiffViews(ctx, exp.JSON, b)
+				a.failDiff(
+					ctx, gdtjson.ErrJSONPathNotEqual, "json",
+					a.subjectDescription(), expected, actual,
+				)
+			}
 			return false
 		}
 	}
@@ -447,24 +1083,52 @@ func (a *assertions) jsonOK(ctx context.Context) bool {
 func (a *assertions) placementOK(ctx context.Context) bool {
 	exp := a.exp
 	if exp.Placement != nil && a.hasSubject() {
-		// TODO(jaypipes): Handle list returns...
-		res, ok := a.r.(*unstructured.Unstructured)
+		objs, ok := a.subjectObjects()
 		if !ok {
-			panic("expected result to be unstructured.Unstructured")
+			a.Fail(AssertionUnsupported("placement", a.subjectDescription()))
+			return false
 		}
-		spread := exp.Placement.Spread
-		if spread != nil {
-			ok = a.placementSpreadOK(ctx, res, spread.Values())
+		// The placement must hold for the Pods of each object returned by
+		// `kube.get`, created by `kube.create`, applied by `kube.apply` or
+		// waited on by `kube.wait`.
+		groups, err := a.placementPodGroups(ctx, objs)
+		if err != nil {
+			a.Fail(err)
+			return false
 		}
-		pack := exp.Placement.Pack
-		if pack != nil {
-			ok = ok && a.placementPackOK(ctx, res, pack.Values())
+		nodes := getNodes(ctx, a.c)
+		pass := true
+		for _, pods := range groups {
+			pods = lo.Filter(pods, func(p pod, _ int) bool {
+				return p.scheduled()
+			})
+			pass = a.podsPlacementOK(ctx, nodes, pods) && pass
 		}
-		return ok
+		return pass
 	}
 	return true
 }
 
+// podsPlacementOK returns true if the supplied scheduled Pods match the
+// Placement conditions, false otherwise
+func (a *assertions) podsPlacementOK(
+	ctx context.Context,
+	nodes []node,
+	pods []pod,
+) bool {
+	ok := true
+	pa := a.exp.Placement
+	if pa.Spread != nil {
+		ok = a.placementSpreadOK(
+			ctx, nodes, pods, pa.Spread.Values(), pa.maxSkew(), pa.MinDomains,
+		)
+	}
+	if pa.Pack != nil {
+		ok = ok && a.placementPackOK(ctx, nodes, pods, pa.Pack.Values())
+	}
+	return ok && a.placementConstraintsOK(ctx, nodes, pods)
+}
+
 // hasSubject returns true if the assertions `r` field (which contains the
 // subject of which we inspect) is not `nil`.
 func (a *assertions) hasSubject() bool {
@@ -475,10 +1139,49 @@ func (a *assertions) hasSubject() bool {
 	case *unstructured.UnstructuredList:
 		v := a.r.(*unstructured.UnstructuredList)
 		return v != nil
+	case []*unstructured.Unstructured:
+		v := a.r.([]*unstructured.Unstructured)
+		return v != nil
+	case *execOutput:
+		v := a.r.(*execOutput)
+		return v != nil
+	case *logsOutput:
+		v := a.r.(*logsOutput)
+		return v != nil
+	case *describeOutput:
+		v := a.r.(*describeOutput)
+		return v != nil
+	case *waitOutput:
+		v := a.r.(*waitOutput)
+		return v != nil
+	case *watchOutput:
+		v := a.r.(*watchOutput)
+		return v != nil
+	case *eventsOutput:
+		v := a.r.(*eventsOutput)
+		return v != nil
 	}
 	return false
 }
 
+// subjectDescription returns a short description of the assertions subject
+// for use in failure messages.
+func (a *assertions) subjectDescription() string {
+	switch a.r.(type) {
+	case *unstructured.UnstructuredList:
+		return "a list of objects"
+	case *execOutput:
+		return "the output of kube.exec"
+	case *logsOutput:
+		return "the output of kube.logs"
+	case *watchOutput:
+		return "the output of kube.watch"
+	case *eventsOutput:
+		return "the output of kube.events"
+	}
+	return fmt.Sprintf("%T", a.r)
+}
+
 // newAssertions returns an assertions object populated with the supplied http
 // spec assertions
 func newAssertions(
@@ -486,12 +1189,19 @@ func newAssertions(
 	exp *Expect,
 	err error,
 	r any,
+	failOnDeprecation bool,
 ) api.Assertions {
+	var warnings []string
+	if c != nil && c.warnings != nil {
+		warnings = c.warnings.warnings()
+	}
 	return &assertions{
-		c:        c,
-		failures: []error{},
-		exp:      exp,
-		err:      err,
-		r:        r,
+		c:                 c,
+		failures:          []error{},
+		exp:               exp,
+		err:               err,
+		r:                 r,
+		warnings:          warnings,
+		failOnDeprecation: failOnDeprecation,
 	}
 }
@@ -0,0 +1,229 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	celoperators "github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// celVarObject is the CEL variable bound to the returned object, or to
+	// the list object when a list was returned.
+	celVarObject = "object"
+	// celVarItems is the CEL variable bound to the returned objects: the
+	// items of a returned list, or a list containing the single returned
+	// object.
+	celVarItems = "items"
+	// celVarVars is the CEL variable bound to the scenario's variables.
+	celVarVars = "vars"
+)
+
+// celEnv returns the CEL environment `assert.cel` expressions are compiled
+// in. The variables are dynamically typed since the objects are
+// unstructured, so type-checking mostly catches syntax errors, unknown
+// variables and functions, and expressions that cannot evaluate to a bool.
+func celEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable(celVarObject, cel.DynType),
+		cel.Variable(celVarItems, cel.ListType(cel.DynType)),
+		cel.Variable(celVarVars, cel.MapType(cel.StringType, cel.DynType)),
+		ext.Strings(),
+		ext.Lists(),
+		ext.Sets(),
+	)
+}
+
+// compileCEL compiles and type-checks the supplied CEL expression, returning
+// an error if the expression is invalid or does not evaluate to a bool.
+func compileCEL(env *cel.Env, expr string) (*cel.Ast, error) {
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	outType := ast.OutputType()
+	if !outType.IsExactType(cel.BoolType) && !outType.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf(
+			"expression must evaluate to a bool but evaluates to %s",
+			outType,
+		)
+	}
+	return ast, nil
+}
+
+// celActivation returns the values of the CEL variables for the supplied
+// objects. The `object` variable is bound to the list object when the
+// subject is a list.
+func (a *assertions) celActivation(ctx context.Context) (map[string]any, bool) {
+	objs, ok := a.subjectObjects()
+	if !ok {
+		return nil, false
+	}
+	items := lo.Map(objs, func(obj *unstructured.Unstructured, _ int) any {
+		return obj.Object
+	})
+	var object any
+	switch r := a.r.(type) {
+	case *unstructured.UnstructuredList:
+		object = r.Object
+	default:
+		if len(objs) == 1 {
+			object = objs[0].Object
+		}
+	}
+	vars := map[string]any{}
+	for k, v := range gdtcontext.PriorRun(ctx) {
+		vars[k] = v
+	}
+	return map[string]any{
+		celVarObject: object,
+		celVarItems:  items,
+		celVarVars:   vars,
+	}, true
+}
+
+// celOK returns true if every `assert.cel` expression evaluates to true
+// against the subject.
+func (a *assertions) celOK(ctx context.Context) bool {
+	exp := a.exp
+	if exp.CEL == nil || !a.hasSubject() {
+		return true
+	}
+	activation, ok := a.celActivation(ctx)
+	if !ok {
+		a.Fail(AssertionUnsupported("cel", a.subjectDescription()))
+		return false
+	}
+	env, err := celEnv()
+	if err != nil {
+		a.Fail(err)
+		return false
+	}
+	res := true
+	for _, expr := range exp.CEL.Values() {
+		ast, err := compileCEL(env, expr)
+		if err != nil {
+			a.Fail(CELFailed(expr, err.Error(), nil))
+			res = false
+			continue
+		}
+		out, err := evalCEL(env, ast, activation)
+		if err != nil {
+			a.Fail(CELFailed(
+				expr, err.Error(), celReferences(env, ast, activation),
+			))
+			res = false
+			continue
+		}
+		if out.Equal(types.True) != types.True {
+			a.Fail(CELFailed(
+				expr,
+				fmt.Sprintf("evaluated to %v", out.Value()),
+				celReferences(env, ast, activation),
+			))
+			res = false
+		}
+	}
+	return res
+}
+
+// evalCEL evaluates the supplied compiled CEL expression.
+func evalCEL(
+	env *cel.Env,
+	ast *cel.Ast,
+	activation map[string]any,
+) (ref.Val, error) {
+	prg, err := env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+	out, _, err := prg.Eval(activation)
+	return out, err
+}
+
+// celReferences returns the values of the fields of the CEL variables that
+// the supplied expression references, e.g. `object.status.readyReplicas`,
+// formatted as `<reference> = <value>` and sorted by reference.
+func celReferences(
+	env *cel.Env,
+	ast *cel.Ast,
+	activation map[string]any,
+) []string {
+	native := ast.NativeRep()
+	refs := map[string]bool{}
+	celast.PreOrderVisit(native.Expr(), celast.NewExprVisitor(func(e celast.Expr) {
+		if !isCELReference(e) {
+			return
+		}
+		s, err := cel.ExprToString(e, native.SourceInfo())
+		if err == nil {
+			refs[s] = true
+		}
+	}))
+	// Only keep the longest references, e.g. `object.status.replicas` and not
+	// also `object.status`, or `has(object.spec.paused)` and not also
+	// `object.spec`.
+	keys := lo.Filter(lo.Keys(refs), func(r string, _ int) bool {
+		for other := range refs {
+			if strings.Contains(other, r+".") ||
+				strings.Contains(other, r+"[") {
+				return false
+			}
+		}
+		return true
+	})
+	sort.Strings(keys)
+	return lo.Map(keys, func(r string, _ int) string {
+		refAst, iss := env.Compile(r)
+		if iss.Err() != nil {
+			return r
+		}
+		out, err := evalCEL(env, refAst, activation)
+		if err != nil {
+			return fmt.Sprintf("%s = <%s>", r, err)
+		}
+		return fmt.Sprintf("%s = %v", r, out.Value())
+	})
+}
+
+// isCELReference returns true if the supplied CEL expression selects a field
+// of, tests for a field of, or indexes into, one of the CEL variables, e.g.
+// `object.spec.containers[0].image` or `has(object.spec.paused)`.
+func isCELReference(e celast.Expr) bool {
+	switch e.Kind() {
+	case celast.SelectKind:
+		return isCELReferenceRoot(e.AsSelect().Operand())
+	case celast.CallKind:
+		call := e.AsCall()
+		if call.FunctionName() != celoperators.Index || len(call.Args()) != 2 {
+			return false
+		}
+		return isCELReferenceRoot(call.Args()[0])
+	}
+	return false
+}
+
+// isCELReferenceRoot returns true if the supplied CEL expression is one of
+// the CEL variables or a reference to one of their fields.
+func isCELReferenceRoot(e celast.Expr) bool {
+	if e.Kind() == celast.IdentKind {
+		return lo.Contains(
+			[]string{celVarObject, celVarItems, celVarVars}, e.AsIdent(),
+		)
+	}
+	return isCELReference(e)
+}
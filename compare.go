@@ -9,40 +9,84 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	gdtcontext "github.com/gdt-dev/core/context"
 	"github.com/gdt-dev/core/debug"
 	"github.com/samber/lo"
 	"gopkg.in/yaml.v3"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
 )
 
 // genericCondition contains fields that are (mostly) common to many Condition
 // objects and that we wish to match against.
 type genericCondition struct {
-	Type   string
-	Status string
-	Reason string
+	Type    string
+	Status  string
+	Reason  string
+	Message string
+	// ObservedGeneration is the `observedGeneration` of the Condition, or
+	// nil if the Condition has none.
+	ObservedGeneration *int64
+	// LastTransitionTime is the `lastTransitionTime` of the Condition, or
+	// the zero time if the Condition has none.
+	LastTransitionTime time.Time
 }
 
-// conditionFound returns a delta describing the differences found between a
-// supplied resource's Conditions and the expected Conditions
+// genericConditionFromMap returns the genericCondition for the supplied
+// Condition fields. Fields with unexpected types are ignored.
+func genericConditionFromMap(condMap map[string]any) genericCondition {
+	gc := genericCondition{}
+	for k, v := range condMap {
+		switch strings.ToLower(k) {
+		case "type":
+			s, _ := v.(string)
+			gc.Type = strings.ToLower(s)
+		case "reason":
+			gc.Reason, _ = v.(string)
+		case "message":
+			gc.Message, _ = v.(string)
+		case "status":
+			s, _ := v.(string)
+			gc.Status = strings.ToLower(s)
+		case "observedgeneration":
+			switch v.(type) {
+			case int, int32, int64:
+				og := toInt64(v)
+				gc.ObservedGeneration = &og
+			}
+		case "lasttransitiontime":
+			s, _ := v.(string)
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				gc.LastTransitionTime = t
+			}
+		}
+	}
+	return gc
+}
+
+// compareConditions returns a delta describing the differences found between
+// a supplied resource's Conditions and the expected Conditions
 func compareConditions(
 	res *unstructured.Unstructured,
 	expected map[string]*ConditionMatch,
 ) *delta {
 	d := &delta{differences: []string{}}
 	conds, found, err := unstructured.NestedSlice(res.Object, "status", "conditions")
-	if found && err != nil {
-		// this means the resource's Status.Conditions is not a slice of
-		// something... which is weird and unexpected, so just panic.
-		msg := fmt.Sprintf(
-			"found a resource %q with a non-slice Status.Conditions field",
-			res.GetKind(),
-		)
-		panic(msg)
+	if err != nil {
+		for condType := range expected {
+			d.Add(fmt.Sprintf(
+				"no condition with type %q found. status.conditions is "+
+					"not a list",
+				condType,
+			))
+		}
+		return d
 	}
 	if (!found || len(conds) == 0) && len(expected) != 0 {
 		for condType := range expected {
@@ -51,81 +95,142 @@ func compareConditions(
 		return d
 	}
 	// construct a map, keyed by condition type, of the condition fields from
-	// the resource so we can do type-based lookups easier.
+	// the resource so we can do type-based lookups easier. Members of
+	// Status.Conditions that are not objects are skipped.
 	gcs := map[string]genericCondition{}
 	for _, condAny := range conds {
 		condMap, ok := condAny.(map[string]any)
 		if !ok {
-			// this means the resource's Status.Conditions is not a slice of
-			// map[string]interface... which is also weird and unexpected, so
-			// just panic.
-			msg := fmt.Sprintf(
-				"found a resource %q with a non-map[string]any "+
-					"Status.Conditions member type: %T",
-				res.GetKind(), condAny,
-			)
-			panic(msg)
-		}
-		gc := genericCondition{}
-		for k, v := range condMap {
-			klow := strings.ToLower(k)
-			switch klow {
-			case "type":
-				gc.Type = strings.ToLower(v.(string))
-			case "reason":
-				gc.Reason = v.(string)
-			case "status":
-				gc.Status = strings.ToLower(v.(string))
-			}
+			continue
 		}
+		gc := genericConditionFromMap(condMap)
 		gcs[gc.Type] = gc
-
 	}
-	for condType, condMatch := range expected {
+	for _, condType := range sortedKeys(expected) {
+		condMatch := expected[condType]
 		ctlow := strings.ToLower(condType)
 		gc, found := gcs[ctlow]
 		if !found {
 			d.Add(fmt.Sprintf("no condition with type %q found", condType))
 			continue
 		}
-		if condMatch.Status != nil {
-			statusValues := condMatch.Status.Values()
-			if gc.Status == "" {
-				msg := fmt.Sprintf(
-					"condition %q had no status. "+
-						"expected status to be one of %s",
-					condType, statusValues,
-				)
-				d.Add(msg)
-				continue
-			}
-			svlow := []string{}
-			for _, sv := range statusValues {
-				svlow = append(svlow, strings.ToLower(sv))
-			}
-			if !lo.Contains(svlow, strings.ToLower(gc.Status)) {
-				msg := fmt.Sprintf(
-					"condition %q had status of %q. "+
-						"expected status to be one of %s",
-					condType, gc.Status, statusValues,
-				)
-				d.Add(msg)
-				continue
-			}
+		collectConditionDifferences(res, condType, condMatch, gc, d)
+	}
+	return d
+}
+
+// collectConditionDifferences compares the supplied Condition of a resource
+// with the expected ConditionMatch and adds any differences to the supplied
+// set of differences.
+func collectConditionDifferences(
+	res *unstructured.Unstructured,
+	condType string,
+	condMatch *ConditionMatch,
+	gc genericCondition,
+	d *delta,
+) {
+	if condMatch.Status != nil {
+		statusValues := condMatch.Status.Values()
+		if gc.Status == "" {
+			msg := fmt.Sprintf(
+				"condition %q had no status. "+
+					"expected status to be one of %s",
+				condType, statusValues,
+			)
+			d.Add(msg)
+			return
 		}
-		if condMatch.Reason != "" {
-			if gc.Reason != condMatch.Reason {
-				msg := fmt.Sprintf(
-					"condition %q had reason of %q. "+
-						"expected reason to be %q",
-					condType, gc.Reason, condMatch.Reason,
-				)
-				d.Add(msg)
-				continue
-			}
+		svlow := []string{}
+		for _, sv := range statusValues {
+			svlow = append(svlow, strings.ToLower(sv))
+		}
+		if !lo.Contains(svlow, strings.ToLower(gc.Status)) {
+			msg := fmt.Sprintf(
+				"condition %q had status of %q. "+
+					"expected status to be one of %s",
+				condType, gc.Status, statusValues,
+			)
+			d.Add(msg)
+			return
+		}
+	}
+	if condMatch.Reason != "" && gc.Reason != condMatch.Reason {
+		msg := fmt.Sprintf(
+			"condition %q had reason of %q. "+
+				"expected reason to be %q",
+			condType, gc.Reason, condMatch.Reason,
+		)
+		d.Add(msg)
+	}
+	if condMatch.ReasonContains != "" &&
+		!strings.Contains(gc.Reason, condMatch.ReasonContains) {
+		d.Add(fmt.Sprintf(
+			"condition %q had reason of %q. expected reason to contain %q",
+			condType, gc.Reason, condMatch.ReasonContains,
+		))
+	}
+	if condMatch.ReasonRegex != "" &&
+		!regexp.MustCompile(condMatch.ReasonRegex).MatchString(gc.Reason) {
+		d.Add(fmt.Sprintf(
+			"condition %q had reason of %q. expected reason to match %q",
+			condType, gc.Reason, condMatch.ReasonRegex,
+		))
+	}
+	if condMatch.Message != "" && gc.Message != condMatch.Message {
+		d.Add(fmt.Sprintf(
+			"condition %q had message of %q. expected message to be %q",
+			condType, gc.Message, condMatch.Message,
+		))
+	}
+	if condMatch.MessageContains != "" &&
+		!strings.Contains(gc.Message, condMatch.MessageContains) {
+		d.Add(fmt.Sprintf(
+			"condition %q had message of %q. expected message to contain %q",
+			condType, gc.Message, condMatch.MessageContains,
+		))
+	}
+	if condMatch.MessageRegex != "" &&
+		!regexp.MustCompile(condMatch.MessageRegex).MatchString(gc.Message) {
+		d.Add(fmt.Sprintf(
+			"condition %q had message of %q. expected message to match %q",
+			condType, gc.Message, condMatch.MessageRegex,
+		))
+	}
+	if condMatch.ObservedGeneration == ObservedGenerationCurrent {
+		generation := res.GetGeneration()
+		switch {
+		case gc.ObservedGeneration == nil:
+			d.Add(fmt.Sprintf(
+				"condition %q had no observedGeneration. expected %d",
+				condType, generation,
+			))
+		case *gc.ObservedGeneration != generation:
+			d.Add(fmt.Sprintf(
+				"condition %q had observedGeneration of %d. expected "+
+					"current generation %d",
+				condType, *gc.ObservedGeneration, generation,
+			))
+		}
+	}
+	if condMatch.StableFor != "" {
+		// The duration was validated at parse time.
+		stableFor, _ := time.ParseDuration(condMatch.StableFor)
+		switch {
+		case gc.LastTransitionTime.IsZero():
+			d.Add(fmt.Sprintf(
+				"condition %q had no lastTransitionTime. expected it to "+
+					"be stable for %s",
+				condType, stableFor,
+			))
+		case time.Since(gc.LastTransitionTime) < stableFor:
+			d.Add(fmt.Sprintf(
+				"condition %q last transitioned at %s. expected it to be "+
+					"stable for %s",
+				condType, gc.LastTransitionTime.Format(time.RFC3339),
+				stableFor,
+			))
 		}
 	}
-	return d
 }
 
 // matchObjectFromAny returns a map[string]any given any of a filepath,
@@ -150,9 +255,19 @@ func matchObjectFromAny(
 				// nothing we can really do.
 				panic(err)
 			}
-		} else {
-			b = []byte(v)
+			// Variable references in the file's content are replaced
+			// before unmarshaling, just like for manifest files, so we
+			// don't replace them again in the map entries below.
+			content := expandManifest(ctx, "kube.assert", string(b), true)
+			var obj map[string]any
+			if err = yaml.Unmarshal([]byte(content), &obj); err != nil {
+				// NOTE: We already validated that the content could be
+				// unmarshaled at parse time.
+				panic(err)
+			}
+			return obj
 		}
+		b = []byte(v)
 		var obj map[string]any
 		if err = yaml.Unmarshal(b, &obj); err != nil {
 			// NOTE(jaypipes): We already validated that the content could be
@@ -228,18 +343,46 @@ func compareResourceToMatchObject(
 	match map[string]any,
 ) *delta {
 	d := &delta{differences: []string{}}
-	collectFieldDifferences("$", match, res.Object, d)
+	collectFieldDifferences("$", match, res.Object, patchMetaForObject(res), "", d)
 	return d
 }
 
 // collectFieldDifferences compares two things and adds any differences between
 // them to a supplied set of differences.
+//
+// The supplied patch metadata, if not nil, describes the fields of the things
+// being compared (or of the items when the things are lists) and is used to
+// look up the Kubernetes patch merge key of list fields. The supplied merge
+// key is the patch merge key of the things being compared when they are
+// lists.
 func collectFieldDifferences(
 	fp string, // the "field path" to the field we are comparing...
 	match any,
 	subject any,
+	meta strategicpatch.LookupPatchMeta,
+	mergeKey string,
 	delta *delta,
 ) {
+	if matchmap, ok := match.(map[string]any); ok {
+		ld, isDirective, err := listDirectiveFrom(matchmap)
+		if err != nil {
+			delta.Add(fmt.Sprintf("%s %s", fp, err))
+			return
+		}
+		if isDirective {
+			collectListDifferences(fp, ld, subject, meta, mergeKey, delta)
+			return
+		}
+		ops, isOperators, err := operatorsFrom(matchmap)
+		if err != nil {
+			delta.Add(fmt.Sprintf("%s %s", fp, err))
+			return
+		}
+		if isOperators {
+			collectOperatorDifferences(fp, ops, subject, delta)
+			return
+		}
+	}
 	if !typesComparable(match, subject) {
 		diff := fmt.Sprintf(
 			"%s non-comparable types: %T and %T.",
@@ -256,11 +399,17 @@ func collectFieldDifferences(
 			subjectv, ok := subjectmap[matchk]
 			newfp := fp + "." + matchk
 			if !ok {
+				if expectsAbsent(matchv) {
+					continue
+				}
 				diff := fmt.Sprintf("%s not present in subject", newfp)
 				delta.Add(diff)
 				continue
 			}
-			collectFieldDifferences(newfp, matchv, subjectv, delta)
+			childMeta, childMergeKey := childPatchMeta(meta, matchk, subjectv)
+			collectFieldDifferences(
+				newfp, matchv, subjectv, childMeta, childMergeKey, delta,
+			)
 		}
 		return
 	case []any:
@@ -278,7 +427,7 @@ func collectFieldDifferences(
 		for x, matchv := range matchlist {
 			subjectv := subjectlist[x]
 			newfp := fmt.Sprintf("%s[%d]", fp, x)
-			collectFieldDifferences(newfp, matchv, subjectv, delta)
+			collectFieldDifferences(newfp, matchv, subjectv, meta, "", delta)
 		}
 		return
 	case int, int8, int16, int32, int64:
@@ -307,6 +456,9 @@ func collectFieldDifferences(
 			mv := toInt64(match)
 			sv, err := strconv.Atoi(subject)
 			if err != nil {
+				if quantitiesEqual(match, subject) {
+					return
+				}
 				diff := fmt.Sprintf(
 					"%s had different values. expected %v but found %v",
 					fp, match, subject,
@@ -321,16 +473,33 @@ func collectFieldDifferences(
 				)
 				delta.Add(diff)
 			}
+		case float32, float64:
+			if !quantitiesEqual(match, subject) {
+				diff := fmt.Sprintf(
+					"%s had different values. expected %v but found %v",
+					fp, match, subject,
+				)
+				delta.Add(diff)
+			}
+		}
+		return
+	case float32, float64:
+		if !quantitiesEqual(match, subject) {
+			diff := fmt.Sprintf(
+				"%s had different values. expected %v but found %v",
+				fp, match, subject,
+			)
+			delta.Add(diff)
 		}
 		return
 	case string:
 		switch subject.(type) {
 		case int, int8, int16, int32, int64,
-			uint, uint8, uint16, uint32, uint64:
+			uint, uint8, uint16, uint32, uint64,
+			float32, float64:
 			mv := match.(string)
-			si := subject.(int)
-			sv := strconv.Itoa(si)
-			if mv != sv {
+			sv := fmt.Sprint(subject)
+			if mv != sv && !quantitiesEqual(match, subject) {
 				diff := fmt.Sprintf(
 					"%s had different values. expected %v but found %v",
 					fp, match, subject,
@@ -339,7 +508,7 @@ func collectFieldDifferences(
 			}
 		case string:
 			mv, _ := match.(string)
-			if mv != subject {
+			if mv != subject && !quantitiesEqual(match, subject) {
 				diff := fmt.Sprintf(
 					"%s had different values. expected %v but found %v",
 					fp, match, subject,
@@ -358,6 +527,52 @@ func collectFieldDifferences(
 	}
 }
 
+// patchMetaForObject returns the patch metadata for the supplied object's
+// kind, or nil if the kind is not a built-in Kubernetes kind, e.g. a custom
+// resource.
+func patchMetaForObject(
+	res *unstructured.Unstructured,
+) strategicpatch.LookupPatchMeta {
+	obj, err := scheme.Scheme.New(res.GroupVersionKind())
+	if err != nil {
+		return nil
+	}
+	meta, err := strategicpatch.NewPatchMetaFromStruct(obj)
+	if err != nil {
+		return nil
+	}
+	return meta
+}
+
+// childPatchMeta returns the patch metadata for the field with the supplied
+// key of a thing described by the supplied patch metadata. When the field's
+// value is a list, the returned patch metadata describes the list's items and
+// the list's patch merge key, if any, is also returned.
+func childPatchMeta(
+	meta strategicpatch.LookupPatchMeta,
+	key string,
+	value any,
+) (strategicpatch.LookupPatchMeta, string) {
+	if meta == nil {
+		return nil, ""
+	}
+	switch value.(type) {
+	case map[string]any:
+		child, _, err := meta.LookupPatchMetadataForStruct(key)
+		if err != nil {
+			return nil, ""
+		}
+		return child, ""
+	case []any:
+		child, pm, err := meta.LookupPatchMetadataForSlice(key)
+		if err != nil {
+			return nil, ""
+		}
+		return child, pm.GetPatchMergeKey()
+	}
+	return nil, ""
+}
+
 // typesComparable returns true if the two supplied things are comparable,
 // false otherwise
 func typesComparable(a, b any) bool {
@@ -366,22 +581,34 @@ func typesComparable(a, b any) bool {
 	at := av.Kind()
 	bt := bv.Kind()
 	switch at {
-	case reflect.Int, reflect.Int8, reflect.Int32, reflect.Int64:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
 		switch bt {
-		case reflect.Int, reflect.Int8, reflect.Int32, reflect.Int64,
-			reflect.String:
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64, reflect.Float32, reflect.Float64, reflect.String:
 			return true
 		default:
 			return false
 		}
-	case reflect.Uint, reflect.Uint8, reflect.Uint32, reflect.Uint64:
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
 		switch bt {
-		case reflect.Uint, reflect.Uint8, reflect.Uint32,
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
 			reflect.Uint64, reflect.String:
 			return true
 		default:
 			return false
 		}
+	case reflect.Float32, reflect.Float64:
+		switch bt {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+			reflect.Uint32, reflect.Uint64, reflect.Float32,
+			reflect.Float64, reflect.String:
+			return true
+		default:
+			return false
+		}
 	case reflect.Complex64, reflect.Complex128:
 		switch bt {
 		case reflect.Complex64, reflect.Complex128, reflect.String:
@@ -391,9 +618,11 @@ func typesComparable(a, b any) bool {
 		}
 	case reflect.String:
 		switch bt {
-		case reflect.Int, reflect.Int8, reflect.Int32, reflect.Int64,
-			reflect.Uint, reflect.Uint8, reflect.Uint32, reflect.Uint64,
-			reflect.Complex64, reflect.Complex128, reflect.String:
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+			reflect.Uint32, reflect.Uint64, reflect.Float32,
+			reflect.Float64, reflect.Complex64, reflect.Complex128,
+			reflect.String:
 			return true
 		default:
 			return false
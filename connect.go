@@ -15,6 +15,7 @@ import (
 	"k8s.io/client-go/discovery"
 	discocached "k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
@@ -89,12 +90,21 @@ func (s *Spec) Config(ctx context.Context) (*rest.Config, error) {
 	).ClientConfig()
 }
 
-// connection is a struct containing a discovery client and a dynamic client
-// that the Spec uses to communicate with Kubernetes.
+// connection is a struct containing a discovery client, a dynamic client and
+// a typed clientset that the Spec uses to communicate with Kubernetes.
 type connection struct {
 	mapper meta.RESTMapper
 	disco  discovery.CachedDiscoveryInterface
 	client dynamic.Interface
+	// cfg is the rest.Config the clients were constructed from. It is needed
+	// for streaming subresources like `pods/exec`.
+	cfg *rest.Config
+	// clientset is a typed client used for core/v1 subresources (exec, logs)
+	// that the dynamic client does not support.
+	clientset kubernetes.Interface
+	// warnings records the warnings returned by the Kubernetes API server in
+	// responses to the requests made with this connection.
+	warnings *warningRecorder
 }
 
 // mappingForGVK returns a RESTMapper for a given GroupVersionKind
@@ -216,6 +226,8 @@ func (s *Spec) connect(ctx context.Context) (*connection, error) {
 	if err != nil {
 		return nil, err
 	}
+	warnings := &warningRecorder{}
+	cfg.WarningHandlerWithContext = warnings
 	c, err := dynamic.NewForConfig(cfg)
 	if err != nil {
 		return nil, err
@@ -224,13 +236,20 @@ func (s *Spec) connect(ctx context.Context) (*connection, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
 	disco := discocached.NewMemCacheClient(discoverer)
 	mapper := restmapper.NewDeferredDiscoveryRESTMapper(disco)
 	expander := restmapper.NewShortcutExpander(mapper, disco, func(s string) { fmt.Fprint(os.Stderr, s) })
 
 	return &connection{
-		mapper: expander,
-		disco:  disco,
-		client: c,
+		mapper:    expander,
+		disco:     disco,
+		client:    c,
+		cfg:       cfg,
+		clientset: cs,
+		warnings:  warnings,
 	}, nil
 }
@@ -30,6 +30,14 @@ type kubeDefaults struct {
 	// Namespace is the name of the Kubernetes namespace to use by default.
 	// This can be overridden with the `Spec.Kube.Namespace` field.
 	Namespace string `yaml:"namespace,omitempty"`
+	// DryRun is the dry-run mode to use by default for create, apply, patch
+	// and delete requests. It must be either `server` or `none`. This can be
+	// overridden with the `Spec.Kube.DryRun` field.
+	DryRun string `yaml:"dry-run,omitempty"`
+	// FailOnDeprecation indicates whether tests should fail when the
+	// Kubernetes API server returns a deprecation warning. This can be
+	// overridden with the `Spec.Kube.FailOnDeprecation` field.
+	FailOnDeprecation bool `yaml:"fail-on-deprecation,omitempty"`
 }
 
 // Defaults is the known HTTP plugin defaults collection
@@ -69,6 +77,9 @@ func (d *Defaults) UnmarshalYAML(node *yaml.Node) error {
 
 // validate determines if any specified defaults are valid.
 func (d *Defaults) validate(node *yaml.Node) error {
+	if d.DryRun != "" && !validDryRun(d.DryRun) {
+		return InvalidDryRunAt(d.DryRun, node)
+	}
 	if d.Config != "" {
 		f, err := os.Open(d.Config)
 		if err != nil {
@@ -0,0 +1,276 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/gdt-dev/core/debug"
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+var (
+	// describeChildResources is a map, keyed by Kind, of the resources that
+	// may contain objects owned (via `metadata.ownerReferences`) by an object
+	// of that Kind.
+	describeChildResources = map[string][]string{
+		"Deployment":  {"replicasets"},
+		"ReplicaSet":  {"pods"},
+		"StatefulSet": {"pods", "controllerrevisions"},
+		"DaemonSet":   {"pods", "controllerrevisions"},
+		"Job":         {"pods"},
+		"CronJob":     {"jobs"},
+		"Service":     {"endpointslices"},
+	}
+	// describeWorkloadKinds contains the Kinds that select Pods using a
+	// `spec.selector` label selector and for which `kube.describe` will
+	// include the status of the selected Pods.
+	describeWorkloadKinds = []string{
+		"Deployment",
+		"ReplicaSet",
+		"StatefulSet",
+		"DaemonSet",
+		"Job",
+	}
+)
+
+// describeOutput is the composite document produced by `kube.describe`. It
+// contains the described object along with related data, similar to what
+// `kubectl describe` shows.
+type describeOutput struct {
+	// object is the described object
+	object *unstructured.Unstructured
+	// events contains the Events whose involvedObject is the described
+	// object, oldest first
+	events []unstructured.Unstructured
+	// children contains the objects having an ownerReference to the
+	// described object
+	children []unstructured.Unstructured
+	// pods contains the Pods selected by the described object when the
+	// object is a workload like a Deployment or StatefulSet. nil otherwise.
+	pods []unstructured.Unstructured
+}
+
+// asMap returns the describe output as a map[string]any with `object`,
+// `events`, `children` and, for workloads, `pods` and `podStatus` keys.
+func (o *describeOutput) asMap() map[string]any {
+	res := map[string]any{
+		"object":   o.object.Object,
+		"events":   objectsAsSlice(o.events),
+		"children": objectsAsSlice(o.children),
+	}
+	if o.pods != nil {
+		res["pods"] = objectsAsSlice(o.pods)
+		res["podStatus"] = podPhaseCounts(o.pods)
+	}
+	return res
+}
+
+// objectsAsSlice returns the Object maps of the supplied unstructured objects
+func objectsAsSlice(objs []unstructured.Unstructured) []any {
+	res := make([]any, len(objs))
+	for x, obj := range objs {
+		res[x] = obj.Object
+	}
+	return res
+}
+
+// podPhaseCounts returns a map, keyed by lowercased Pod phase, of the number
+// of supplied Pods in that phase.
+func podPhaseCounts(pods []unstructured.Unstructured) map[string]any {
+	res := map[string]any{
+		"pending":   int64(0),
+		"running":   int64(0),
+		"succeeded": int64(0),
+		"failed":    int64(0),
+		"unknown":   int64(0),
+	}
+	for _, p := range pods {
+		phase, _, _ := unstructured.NestedString(p.Object, "status", "phase")
+		phase = strings.ToLower(phase)
+		if _, ok := res[phase]; !ok {
+			phase = "unknown"
+		}
+		res[phase] = res[phase].(int64) + 1
+	}
+	return res
+}
+
+// describe gets the target object along with its Events, owned children and,
+// for workloads, the selected Pods, and populates `out` with a
+// `*describeOutput`.
+func (a *Action) describe(
+	ctx context.Context,
+	c *connection,
+	ns string,
+	out *interface{},
+) error {
+	arg := a.Describe.Arg
+	argRep := gdtcontext.ReplaceVariables(ctx, arg)
+	if arg != argRep {
+		debug.Printf(
+			ctx,
+			"kube.describe: replaced arg: %s -> %s",
+			arg, argRep,
+		)
+	}
+	name := a.Describe.Name
+	nameRep := gdtcontext.ReplaceVariables(ctx, name)
+	if name != nameRep {
+		debug.Printf(
+			ctx,
+			"kube.describe: replaced name: %s -> %s",
+			name, nameRep,
+		)
+	}
+	res, err := c.gvrFromArg(argRep)
+	if err != nil {
+		return err
+	}
+	obj, err := a.doGet(ctx, c, res, ns, nameRep)
+	if err != nil {
+		return err
+	}
+	d := &describeOutput{object: obj}
+	if d.events, err = describeEvents(ctx, c, obj); err != nil {
+		return err
+	}
+	if d.children, err = describeChildren(ctx, c, obj); err != nil {
+		return err
+	}
+	if lo.Contains(describeWorkloadKinds, obj.GetKind()) {
+		if d.pods, err = describePods(ctx, c, obj); err != nil {
+			return err
+		}
+	}
+	*out = d
+	return nil
+}
+
+// describeEvents returns the core/v1 Events whose involvedObject is the
+// supplied object, sorted oldest first.
+func describeEvents(
+	ctx context.Context,
+	c *connection,
+	obj *unstructured.Unstructured,
+) ([]unstructured.Unstructured, error) {
+	res, err := c.gvrFromArg("events")
+	if err != nil {
+		return nil, err
+	}
+	sel := fields.Set{"involvedObject.uid": string(obj.GetUID())}.String()
+	debug.Printf(
+		ctx, "kube.describe: events (ns: %s, selector: %s)",
+		obj.GetNamespace(), sel,
+	)
+	// NOTE: Events for non-namespaced objects like Nodes are recorded in the
+	// "default" namespace, so we search all namespaces when the described
+	// object has no namespace.
+	list, err := c.client.Resource(res).Namespace(obj.GetNamespace()).List(
+		ctx, metav1.ListOptions{FieldSelector: sel},
+	)
+	if err != nil {
+		return nil, err
+	}
+	events := list.Items
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTimestamp(&events[i]).Before(eventTimestamp(&events[j]))
+	})
+	return events, nil
+}
+
+// eventTimestamp returns the most relevant timestamp for the supplied core/v1
+// or events.k8s.io/v1 Event, preferring the time the Event was last observed,
+// then the time it was first observed and finally the Event's creation
+// timestamp.
+func eventTimestamp(ev *unstructured.Unstructured) time.Time {
+	for _, field := range [][]string{
+		{"series", "lastObservedTime"},
+		{"lastTimestamp"},
+		{"deprecatedLastTimestamp"},
+		{"eventTime"},
+		{"firstTimestamp"},
+		{"deprecatedFirstTimestamp"},
+	} {
+		v, _, _ := unstructured.NestedString(ev.Object, field...)
+		if v == "" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t
+		}
+	}
+	return ev.GetCreationTimestamp().Time
+}
+
+// describeChildren returns the objects that have an ownerReference pointing
+// at the supplied object.
+func describeChildren(
+	ctx context.Context,
+	c *connection,
+	obj *unstructured.Unstructured,
+) ([]unstructured.Unstructured, error) {
+	children := []unstructured.Unstructured{}
+	for _, arg := range describeChildResources[obj.GetKind()] {
+		res, err := c.gvrFromArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		debug.Printf(
+			ctx, "kube.describe: %s owned by %s/%s (ns: %s)",
+			arg, obj.GetKind(), obj.GetName(), obj.GetNamespace(),
+		)
+		list, err := c.client.Resource(res).Namespace(obj.GetNamespace()).List(
+			ctx, metav1.ListOptions{},
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			for _, ref := range item.GetOwnerReferences() {
+				if ref.UID == obj.GetUID() {
+					children = append(children, item)
+					break
+				}
+			}
+		}
+	}
+	return children, nil
+}
+
+// describePods returns the Pods selected by the supplied workload object's
+// `spec.selector`.
+func describePods(
+	ctx context.Context,
+	c *connection,
+	obj *unstructured.Unstructured,
+) ([]unstructured.Unstructured, error) {
+	sel, err := workloadSelector(obj)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.gvrFromArg("pods")
+	if err != nil {
+		return nil, err
+	}
+	debug.Printf(
+		ctx, "kube.describe: pods (ns: %s, selector: %s)",
+		obj.GetNamespace(), sel,
+	)
+	list, err := c.client.Resource(res).Namespace(obj.GetNamespace()).List(
+		ctx, metav1.ListOptions{LabelSelector: sel.String()},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
@@ -0,0 +1,560 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	gdtjson "github.com/gdt-dev/core/assertion/json"
+	"github.com/gdt-dev/core/debug"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/theory/jsonpath"
+	"github.com/theory/jsonpath/spec"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+var (
+	// ColorDiffs indicates that the diffs shown when `assert.matches`,
+	// `assert.conditions`, `assert.json` and `assert.snapshot` fail are
+	// colored using ANSI escape sequences. It defaults to true unless the
+	// `NO_COLOR` environment variable is set.
+	ColorDiffs = os.Getenv("NO_COLOR") == ""
+)
+
+const (
+	// diffContext is the number of unchanged lines shown around each change.
+	diffContext = 3
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiCyan    = "\x1b[36m"
+	ansiFaint   = "\x1b[2m"
+)
+
+// colorize wraps the supplied text in the supplied ANSI escape sequence when
+// ColorDiffs is true.
+func colorize(color string, text string) string {
+	if !ColorDiffs {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+// failDiff adds a failure wrapping the supplied error with a diff between the
+// supplied expected and actual values of the supplied subject, e.g.
+// "Deployment/nginx", and writes the diff to the debug output.
+func (a *assertions) failDiff(
+	ctx context.Context,
+	err error,
+	assertion string,
+	subject string,
+	expected any,
+	actual any,
+) {
+	diff := renderDiff("expected", "actual", expected, actual)
+	if diff == "" {
+		return
+	}
+	debug.Printf(ctx, "kube.assert.%s: %s differs:\n%s", assertion, subject, diff)
+	a.Fail(Diff(err, subject, diff))
+}
+
+// renderDiff returns a unified diff between the supplied values rendered as
+// YAML, or an empty string if they render the same.
+func renderDiff(fromName, toName string, expected, actual any) string {
+	from, err := marshalYAML(expected)
+	if err != nil {
+		return ""
+	}
+	to, err := marshalYAML(actual)
+	if err != nil {
+		return ""
+	}
+	return renderYAMLDiff(fromName, toName, from, to)
+}
+
+// marshalYAML returns the supplied values as YAML documents separated by
+// `---`, with map keys sorted so that the output is stable.
+func marshalYAML(docs ...any) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return "", err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderYAMLDiff returns a unified diff between the supplied YAML texts, or
+// an empty string if they are the same. Each removed or added line that
+// contains a field is annotated with the JSON path of the field, e.g.
+// `# $.spec.replicas`.
+func renderYAMLDiff(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+	fromLines := splitLines(from)
+	toLines := splitLines(to)
+	fromPaths := yamlLinePaths(from)
+	toPaths := yamlLinePaths(to)
+	var b strings.Builder
+	b.WriteString(colorize(ansiBold, "--- "+fromName) + "\n")
+	b.WriteString(colorize(ansiBold, "+++ "+toName) + "\n")
+	m := difflib.NewMatcher(fromLines, toLines)
+	for _, group := range m.GetGroupedOpCodes(diffContext) {
+		first, last := group[0], group[len(group)-1]
+		b.WriteString(colorize(ansiCyan, fmt.Sprintf(
+			"@@ -%s +%s @@",
+			hunkRange(first.I1, last.I2), hunkRange(first.J1, last.J2),
+		)) + "\n")
+		for _, c := range group {
+			if c.Tag == 'e' {
+				for _, line := range fromLines[c.I1:c.I2] {
+					b.WriteString(" " + line + "\n")
+				}
+				continue
+			}
+			if c.Tag == 'r' || c.Tag == 'd' {
+				for x := c.I1; x < c.I2; x++ {
+					b.WriteString(diffLine(ansiRed, "-", fromLines[x], fromPaths[x+1]))
+				}
+			}
+			if c.Tag == 'r' || c.Tag == 'i' {
+				for x := c.J1; x < c.J2; x++ {
+					b.WriteString(diffLine(ansiGreen, "+", toLines[x], toPaths[x+1]))
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// diffLine returns a removed or added line of a diff, annotated with the
+// supplied JSON path, if any.
+func diffLine(color string, prefix string, line string, path string) string {
+	s := colorize(color, prefix+line)
+	if path != "" {
+		s += colorize(ansiFaint, "  # "+path)
+	}
+	return s + "\n"
+}
+
+// hunkRange returns the range of lines of a unified diff hunk, e.g. `3,4`,
+// for the supplied zero-based start and stop lines.
+func hunkRange(start, stop int) string {
+	beginning := start + 1
+	length := stop - start
+	if length == 1 {
+		return fmt.Sprintf("%d", beginning)
+	}
+	if length == 0 {
+		beginning--
+	}
+	return fmt.Sprintf("%d,%d", beginning, length)
+}
+
+// splitLines returns the lines of the supplied text, without line endings.
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// yamlLinePaths returns a map, keyed by one-based line number, of the JSON
+// paths of the fields and list items on each line of the supplied YAML
+// text. When the text contains more than one document, the paths start with
+// the index of the document, e.g. `$[1].metadata.name`.
+func yamlLinePaths(text string) map[int]string {
+	docs := []*yaml.Node{}
+	dec := yaml.NewDecoder(strings.NewReader(text))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return map[int]string{}
+		}
+		docs = append(docs, &doc)
+	}
+	paths := map[int]string{}
+	for x, doc := range docs {
+		root := "$"
+		if len(docs) > 1 {
+			root = fmt.Sprintf("$[%d]", x)
+		}
+		for _, n := range doc.Content {
+			collectLinePaths(root, n, paths)
+		}
+	}
+	return paths
+}
+
+// collectLinePaths records the JSON paths of the fields and list items of
+// the supplied YAML node in the supplied map, keyed by line number. Fields
+// nested in list items are recorded after, and so replace, the list items
+// they share a line with.
+func collectLinePaths(path string, n *yaml.Node, paths map[int]string) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for x := 0; x+1 < len(n.Content); x += 2 {
+			key, val := n.Content[x], n.Content[x+1]
+			fieldPath := path + "." + key.Value
+			switch {
+			case key.Value == "$" || strings.HasPrefix(key.Value, "$.") ||
+				strings.HasPrefix(key.Value, "$["):
+				// The key is itself a JSONPath expression, e.g. an
+				// `assert.json.paths` expression that selected nothing.
+				fieldPath = key.Value
+			case strings.HasPrefix(key.Value, "$"):
+				// The key is a `matches` operator or list directive, e.g.
+				// `$gte`, which applies to the field it is nested in.
+				fieldPath = path
+			}
+			paths[key.Line] = fieldPath
+			collectLinePaths(fieldPath, val, paths)
+		}
+	case yaml.SequenceNode:
+		for x, item := range n.Content {
+			itemPath := fmt.Sprintf("%s[%d]", path, x)
+			paths[item.Line] = itemPath
+			collectLinePaths(itemPath, item, paths)
+		}
+	}
+}
+
+// matchesDiffViews returns the parts of the supplied `matches` content and
+// object that were compared, so that a diff between them shows only the
+// asserted subtree of the object.
+func matchesDiffViews(
+	obj *unstructured.Unstructured,
+	match map[string]any,
+) (any, any) {
+	return diffViews(match, obj.Object, patchMetaForObject(obj), "")
+}
+
+// diffViews returns the expected and actual views of the supplied `matches`
+// content and subject. Fields of the subject that are not in the `matches`
+// content are left out of both views. Where the subject satisfies the
+// `matches` content, e.g. a field satisfying an operator expression or a
+// resource quantity equal to a differently formatted one, both views contain
+// the subject's value, so that only the differences appear in a diff.
+func diffViews(
+	match any,
+	subject any,
+	meta strategicpatch.LookupPatchMeta,
+	mergeKey string,
+) (any, any) {
+	d := &delta{differences: []string{}}
+	collectFieldDifferences("$", match, subject, meta, mergeKey, d)
+	if d.Empty() {
+		pruned := pruneToMatch(match, subject)
+		return pruned, pruned
+	}
+	switch match := match.(type) {
+	case map[string]any:
+		if _, isDirective, _ := listDirectiveFrom(match); isDirective {
+			return match, subject
+		}
+		if _, isOperators, _ := operatorsFrom(match); isOperators {
+			return match, subject
+		}
+		subjectmap, ok := subject.(map[string]any)
+		if !ok {
+			return match, subject
+		}
+		expected := map[string]any{}
+		actual := map[string]any{}
+		for k, matchv := range match {
+			subjectv, ok := subjectmap[k]
+			if !ok {
+				if !expectsAbsent(matchv) {
+					expected[k] = matchv
+				}
+				continue
+			}
+			childMeta, childMergeKey := childPatchMeta(meta, k, subjectv)
+			expected[k], actual[k] = diffViews(
+				matchv, subjectv, childMeta, childMergeKey,
+			)
+		}
+		return expected, actual
+	case []any:
+		subjectlist, ok := subject.([]any)
+		if !ok || len(match) != len(subjectlist) {
+			return match, subject
+		}
+		expected := make([]any, len(match))
+		actual := make([]any, len(match))
+		for x, matchv := range match {
+			expected[x], actual[x] = diffViews(matchv, subjectlist[x], meta, "")
+		}
+		return expected, actual
+	}
+	return match, subject
+}
+
+// pruneToMatch returns the supplied subject without the fields that are not
+// in the supplied `matches` content.
+func pruneToMatch(match any, subject any) any {
+	switch match := match.(type) {
+	case map[string]any:
+		if _, isDirective, _ := listDirectiveFrom(match); isDirective {
+			return subject
+		}
+		if _, isOperators, _ := operatorsFrom(match); isOperators {
+			return subject
+		}
+		subjectmap, ok := subject.(map[string]any)
+		if !ok {
+			return subject
+		}
+		pruned := map[string]any{}
+		for k, matchv := range match {
+			if subjectv, ok := subjectmap[k]; ok {
+				pruned[k] = pruneToMatch(matchv, subjectv)
+			}
+		}
+		return pruned
+	case []any:
+		subjectlist, ok := subject.([]any)
+		if !ok || len(match) != len(subjectlist) {
+			return subject
+		}
+		pruned := make([]any, len(match))
+		for x, matchv := range match {
+			pruned[x] = pruneToMatch(matchv, subjectlist[x])
+		}
+		return pruned
+	}
+	return subject
+}
+
+// conditionFields are the `assert.conditions` fields, in the order they are
+// shown in diffs, along with the Condition fields they assert on.
+var conditionFields = []struct {
+	key   string
+	field string
+}{
+	{"status", "status"},
+	{"reason", "reason"},
+	{"reason-contains", "reason"},
+	{"reason-regex", "reason"},
+	{"message", "message"},
+	{"message-contains", "message"},
+	{"message-regex", "message"},
+	{"observed-generation", "observedGeneration"},
+	{"stable-for", "lastTransitionTime"},
+}
+
+// conditionsDiffViews returns the expected and actual views of the
+// `status.conditions` of the supplied object for the Conditions that did not
+// match. An expected field the Condition satisfies is shown with the
+// Condition's value, so that only the differences appear in a diff.
+func conditionsDiffViews(
+	obj *unstructured.Unstructured,
+	expected map[string]*ConditionMatch,
+) (any, any) {
+	conds, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	byType := map[string]map[string]any{}
+	for _, condAny := range conds {
+		if condMap, ok := condAny.(map[string]any); ok {
+			byType[strings.ToLower(fmt.Sprint(condMap["type"]))] = condMap
+		}
+	}
+	expConds := []any{}
+	actConds := []any{}
+	for _, condType := range sortedKeys(expected) {
+		condMatch := expected[condType]
+		condMap, found := byType[strings.ToLower(condType)]
+		if found {
+			gc := genericConditionFromMap(condMap)
+			d := &delta{differences: []string{}}
+			collectConditionDifferences(obj, condType, condMatch, gc, d)
+			if d.Empty() {
+				continue
+			}
+		}
+		// The expected Condition types are lowercased at parse time, so the
+		// Condition's own type is shown when it has one.
+		expCond := map[string]any{"type": condType}
+		actCond := map[string]any{}
+		if found {
+			expCond["type"] = condMap["type"]
+			actCond["type"] = condMap["type"]
+		}
+		for _, f := range conditionFields {
+			expv, only := conditionMatchField(&condMatch.conditionMatch, f.key)
+			if only == nil {
+				continue
+			}
+			actv, hasField := condMap[f.field]
+			if hasField {
+				actCond[f.field] = actv
+				d := &delta{differences: []string{}}
+				collectConditionDifferences(
+					obj, condType, only, genericConditionFromMap(condMap), d,
+				)
+				if d.Empty() {
+					expCond[f.field] = actv
+					continue
+				}
+			}
+			expCond[f.key] = expv
+		}
+		expConds = append(expConds, expCond)
+		if found {
+			actConds = append(actConds, actCond)
+		}
+	}
+	return map[string]any{"status": map[string]any{"conditions": expConds}},
+		map[string]any{"status": map[string]any{"conditions": actConds}}
+}
+
+// conditionMatchField returns the value of the supplied `assert.conditions`
+// field of the supplied conditionMatch along with a ConditionMatch asserting
+// only that field, or nil if the field is not set.
+func conditionMatchField(c *conditionMatch, key string) (any, *ConditionMatch) {
+	only := conditionMatch{}
+	var v any
+	switch key {
+	case "status":
+		if c.Status == nil {
+			return nil, nil
+		}
+		only.Status = c.Status
+		v = c.Status.Values()
+		if statuses := c.Status.Values(); len(statuses) == 1 {
+			v = statuses[0]
+		}
+	case "reason":
+		only.Reason, v = c.Reason, c.Reason
+	case "reason-contains":
+		only.ReasonContains, v = c.ReasonContains, c.ReasonContains
+	case "reason-regex":
+		only.ReasonRegex, v = c.ReasonRegex, c.ReasonRegex
+	case "message":
+		only.Message, v = c.Message, c.Message
+	case "message-contains":
+		only.MessageContains, v = c.MessageContains, c.MessageContains
+	case "message-regex":
+		only.MessageRegex, v = c.MessageRegex, c.MessageRegex
+	case "observed-generation":
+		only.ObservedGeneration, v = c.ObservedGeneration, c.ObservedGeneration
+	case "stable-for":
+		only.StableFor, v = c.StableFor, c.StableFor
+	}
+	if v == "" {
+		return nil, nil
+	}
+	return v, &ConditionMatch{only}
+}
+
+// jsonDiffViews returns the expected and actual views of the fields selected
+// by the `assert.json.paths` JSONPath expressions in the supplied JSON
+// content. A field whose value is the expected value is shown in both views,
+// so that only the differences appear in a diff.
+func jsonDiffViews(
+	ctx context.Context,
+	exp *gdtjson.Expect,
+	content []byte,
+) (any, any) {
+	var v any
+	if err := json.Unmarshal(content, &v); err != nil {
+		return nil, nil
+	}
+	var expected, actual any
+	paths := make([]string, 0, len(exp.Paths))
+	for path := range exp.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		expVal := exp.Paths[path]
+		p, err := jsonpath.Parse(path)
+		if err != nil {
+			continue
+		}
+		located := p.SelectLocated(v)
+		if len(located) == 0 {
+			// The path is shown as a field since it selected nothing.
+			expected = putAtPath(expected, spec.NormalizedPath{spec.Name(path)}, expVal)
+			continue
+		}
+		got := located[0]
+		ja := gdtjson.New(
+			&gdtjson.Expect{Paths: map[string]string{path: expVal}}, content,
+		)
+		if ja.OK(ctx) {
+			expected = putAtPath(expected, got.Path, got.Node)
+		} else {
+			expected = putAtPath(expected, got.Path, typedExpectedValue(expVal, got.Node))
+		}
+		actual = putAtPath(actual, got.Path, got.Node)
+	}
+	return expected, actual
+}
+
+// typedExpectedValue returns the supplied `assert.json.paths` expected value
+// as a number or bool when the supplied actual value is not a string, so
+// that, for example, `3` is not shown as `"3"` in diffs.
+func typedExpectedValue(expVal string, got any) any {
+	if _, isString := got.(string); isString {
+		return expVal
+	}
+	var typed any
+	if err := yaml.Unmarshal([]byte(expVal), &typed); err != nil {
+		return expVal
+	}
+	switch typed.(type) {
+	case int, float64, bool:
+		return typed
+	}
+	return expVal
+}
+
+// putAtPath returns the supplied map or list with the supplied value set at
+// the supplied normalized path, creating the maps and lists along the path.
+// Lists are padded with nil items up to the path's index.
+func putAtPath(subject any, path spec.NormalizedPath, value any) any {
+	if len(path) == 0 {
+		return value
+	}
+	switch sel := path[0].(type) {
+	case spec.Name:
+		m, ok := subject.(map[string]any)
+		if !ok {
+			m = map[string]any{}
+		}
+		m[string(sel)] = putAtPath(m[string(sel)], path[1:], value)
+		return m
+	case spec.Index:
+		l, _ := subject.([]any)
+		for len(l) <= int(sel) {
+			l = append(l, nil)
+		}
+		l[sel] = putAtPath(l[sel], path[1:], value)
+		return l
+	}
+	return subject
+}
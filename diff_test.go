@@ -0,0 +1,256 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// testPods returns a list of two Pods whose `app` label is their name.
+func testPods() *unstructured.UnstructuredList {
+	pod := func(name, phase string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]any{
+				"name":   name,
+				"labels": map[string]any{"app": name},
+			},
+			"status": map[string]any{
+				"phase": phase,
+				"conditions": []any{
+					map[string]any{
+						"type":   "Ready",
+						"status": "False",
+						"reason": "ContainersNotReady",
+					},
+				},
+			},
+		}}
+	}
+	return &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			pod("nginx", "Running"),
+			pod("cache", "Pending"),
+		},
+	}
+}
+
+func TestListFailuresIncludeDiff(t *testing.T) {
+	colorDiffs := ColorDiffs
+	ColorDiffs = false
+	t.Cleanup(func() { ColorDiffs = colorDiffs })
+
+	tests := []struct {
+		name    string
+		assert  string
+		err     error
+		subject string
+	}{
+		{
+			name: "matches exactly",
+			assert: `
+matches:
+  exactly:
+    - metadata:
+        name: nginx
+      status:
+        phase: Succeeded
+    - metadata:
+        name: cache
+`,
+			err:     ErrMatchesNotEqual,
+			subject: "Pod/nginx",
+		},
+		{
+			name: "matches any diffs the closest item",
+			assert: `
+matches:
+  any:
+    metadata:
+      name: cache
+      labels:
+        app: cache
+    status:
+      phase: Running
+`,
+			err:     ErrMatchesNoItem,
+			subject: "Pod/cache",
+		},
+		{
+			name: "conditions any diffs the closest item",
+			assert: `
+conditions:
+  any:
+    ready:
+      status: true
+`,
+			err:     ErrConditionsNoItem,
+			subject: "Pod/nginx",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			var exp Expect
+			require.Nil(yaml.Unmarshal([]byte(tt.assert), &exp))
+
+			a := newAssertions(nil, &exp, nil, testPods(), false)
+			require.False(a.OK(context.TODO()))
+			failures := a.Failures()
+			require.Len(failures, 2)
+			last := failures[len(failures)-1]
+			assert.ErrorIs(t, last, tt.err)
+			assert.Contains(t, last.Error(), tt.subject+":\n--- expected\n+++ actual\n")
+		})
+	}
+}
+
+func TestYAMLLinePaths(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		exp  map[int]string
+	}{
+		{
+			name: "nested fields and list items",
+			text: `spec:
+  replicas: 2
+  containers:
+    - name: nginx
+      image: nginx:1.25
+`,
+			exp: map[int]string{
+				1: "$.spec",
+				2: "$.spec.replicas",
+				3: "$.spec.containers",
+				4: "$.spec.containers[0].name",
+				5: "$.spec.containers[0].image",
+			},
+		},
+		{
+			name: "operators apply to the parent field",
+			text: `status:
+  readyReplicas:
+    $gte: 2
+`,
+			exp: map[int]string{
+				1: "$.status",
+				2: "$.status.readyReplicas",
+				3: "$.status.readyReplicas",
+			},
+		},
+		{
+			name: "JSONPath expression keys",
+			text: `$.data.foo: bar
+`,
+			exp: map[int]string{
+				1: "$.data.foo",
+			},
+		},
+		{
+			name: "several documents",
+			text: `metadata:
+  name: a
+---
+metadata:
+  name: b
+`,
+			exp: map[int]string{
+				1: "$[0].metadata",
+				2: "$[0].metadata.name",
+				4: "$[1].metadata",
+				5: "$[1].metadata.name",
+			},
+		},
+		{
+			name: "invalid YAML",
+			text: "a: [",
+			exp:  map[int]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.exp, yamlLinePaths(tt.text))
+		})
+	}
+}
+
+func TestRenderYAMLDiff(t *testing.T) {
+	colorDiffs := ColorDiffs
+	ColorDiffs = false
+	t.Cleanup(func() { ColorDiffs = colorDiffs })
+
+	from := `spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - image: nginx:1.26
+          name: nginx
+`
+	to := `spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - image: nginx:1.25
+          name: nginx
+`
+	exp := `--- expected
++++ actual
+@@ -1,7 +1,7 @@
+ spec:
+-  replicas: 3  # $.spec.replicas
++  replicas: 2  # $.spec.replicas
+   template:
+     spec:
+       containers:
+-        - image: nginx:1.26  # $.spec.template.spec.containers[0].image
++        - image: nginx:1.25  # $.spec.template.spec.containers[0].image
+           name: nginx
+`
+	assert.Equal(t, exp, renderYAMLDiff("expected", "actual", from, to))
+	assert.Empty(t, renderYAMLDiff("expected", "actual", from, from))
+}
+
+func TestRenderDiffSatisfiedOperator(t *testing.T) {
+	colorDiffs := ColorDiffs
+	ColorDiffs = false
+	t.Cleanup(func() { ColorDiffs = colorDiffs })
+
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]any{"name": "nginx"},
+		"status": map[string]any{
+			"readyReplicas": int64(3),
+			"replicas":      int64(3),
+		},
+	}}
+	match := map[string]any{
+		"status": map[string]any{
+			"readyReplicas": map[string]any{"$gte": 2},
+			"replicas":      4,
+		},
+	}
+	expected, actual := matchesDiffViews(obj, match)
+	exp := `--- expected
++++ actual
+@@ -1,3 +1,3 @@
+ status:
+   readyReplicas: 3
+-  replicas: 4  # $.status.replicas
++  replicas: 3  # $.status.replicas
+`
+	assert.Equal(t, exp, renderDiff("expected", "actual", expected, actual))
+}
@@ -6,6 +6,9 @@ package kube
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gdt-dev/core/api"
 )
@@ -32,12 +35,218 @@ var (
 		"%w: match field not equal",
 		api.ErrFailure,
 	)
+	// ErrMatchesObjectNotIdentified is returned when a `kube.assert.matches`
+	// object did not identify which of several created or applied objects it
+	// should be matched against.
+	ErrMatchesObjectNotIdentified = fmt.Errorf(
+		"%w: matches object not identified",
+		api.ErrFailure,
+	)
+	// ErrMatchesNoItem is returned when no item of a list matched a
+	// `kube.assert.matches.any` object.
+	ErrMatchesNoItem = fmt.Errorf(
+		"%w: no list item matched",
+		api.ErrFailure,
+	)
+	// ErrMatchesItemMatched is returned when an item of a list matched a
+	// `kube.assert.matches.none` object.
+	ErrMatchesItemMatched = fmt.Errorf(
+		"%w: unexpected list item matched",
+		api.ErrFailure,
+	)
+	// ErrMatchesItemsNotEqual is returned when the items of a list were not
+	// exactly the items identified in a `kube.assert.matches.exactly` list.
+	ErrMatchesItemsNotEqual = fmt.Errorf(
+		"%w: list items not equal",
+		api.ErrFailure,
+	)
 	// ErrConditionDoesNotMatch is returned when we failed to match a resource to an
 	// Condition match expression in a `kube.assert.matches` object.
 	ErrConditionDoesNotMatch = fmt.Errorf(
 		"%w: condition does not match expectation",
 		api.ErrFailure,
 	)
+	// ErrRegexNotMatched is returned when a regular expression in a
+	// `regex` assertion did not match the contents of a pipe or logs.
+	ErrRegexNotMatched = fmt.Errorf(
+		"%w: regex not matched",
+		api.ErrFailure,
+	)
+	// ErrWaitNotConverged is returned when an object waited on by
+	// `kube.wait` did not reach the desired state before the timeout.
+	ErrWaitNotConverged = fmt.Errorf(
+		"%w: wait condition not met",
+		api.ErrFailure,
+	)
+	// ErrWatchUntilNotReached is returned when the terminal predicate of a
+	// `kube.watch` did not hold before the watch ended.
+	ErrWatchUntilNotReached = fmt.Errorf(
+		"%w: watch until predicate not reached",
+		api.ErrFailure,
+	)
+	// ErrWatchSequenceNotMatched is returned when the events recorded by a
+	// `kube.watch` did not match an `assert.watch.sequence`.
+	ErrWatchSequenceNotMatched = fmt.Errorf(
+		"%w: watch sequence not matched",
+		api.ErrFailure,
+	)
+	// ErrWatchNeverMatched is returned when an event recorded by a
+	// `kube.watch` matched an `assert.watch.never` fragment.
+	ErrWatchNeverMatched = fmt.Errorf(
+		"%w: watch event matched never predicate",
+		api.ErrFailure,
+	)
+	// ErrWatchEventCountNotEqual is returned when the number of events of a
+	// type recorded by a `kube.watch` was not the expected number.
+	ErrWatchEventCountNotEqual = fmt.Errorf(
+		"%w: watch event count not equal",
+		api.ErrFailure,
+	)
+	// ErrWatchDurationNotElapsed is returned when the test spec's timeout
+	// stopped a `kube.watch` before its `duration` elapsed, so the recorded
+	// events only cover part of the requested window.
+	ErrWatchDurationNotElapsed = fmt.Errorf(
+		"%w: watch duration did not elapse",
+		api.ErrFailure,
+	)
+	// ErrEventReasonNotFound is returned when no Event fetched by a
+	// `kube.events` had an `assert.events.has-reason` reason.
+	ErrEventReasonNotFound = fmt.Errorf(
+		"%w: event reason not found",
+		api.ErrFailure,
+	)
+	// ErrUnexpectedWarningEvent is returned when a Warning Event was fetched
+	// by a `kube.events` with `assert.events.no-warnings`.
+	ErrUnexpectedWarningEvent = fmt.Errorf(
+		"%w: unexpected warning event",
+		api.ErrFailure,
+	)
+	// ErrEventCountTooLow is returned when the Events fetched by a
+	// `kube.events` occurred fewer times than `assert.events.count`.
+	ErrEventCountTooLow = fmt.Errorf(
+		"%w: event count too low",
+		api.ErrFailure,
+	)
+	// ErrAssertionUnsupported is returned when an assertion cannot be made
+	// about the output of the action, e.g. `kube.assert.matches` for the
+	// output of a `kube.exec`.
+	ErrAssertionUnsupported = fmt.Errorf(
+		"%w: assertion not supported",
+		api.ErrFailure,
+	)
+	// ErrExpectedStatus is returned when we expected the Kubernetes API server
+	// to reject a request with a `kube.assert.status` but it did not.
+	ErrExpectedStatus = fmt.Errorf(
+		"%w: expected error status",
+		api.ErrFailure,
+	)
+	// ErrStatusDoesNotMatch is returned when the Status returned by the
+	// Kubernetes API server did not match the `kube.assert.status`
+	// expectation.
+	ErrStatusDoesNotMatch = fmt.Errorf(
+		"%w: status does not match expectation",
+		api.ErrFailure,
+	)
+	// ErrExpectedConflict is returned when we expected a field manager
+	// conflict from a `kube.apply` but did not get one.
+	ErrExpectedConflict = fmt.Errorf(
+		"%w: expected field manager conflict",
+		api.ErrFailure,
+	)
+	// ErrConflictDoesNotMatch is returned when a field manager conflict did
+	// not match the `kube.assert.conflict` expectation.
+	ErrConflictDoesNotMatch = fmt.Errorf(
+		"%w: field manager conflict does not match expectation",
+		api.ErrFailure,
+	)
+	// ErrManagedFieldNotOwned is returned when a field in an object's
+	// `metadata.managedFields` was not owned by the field manager in a
+	// `kube.assert.managed-fields` object.
+	ErrManagedFieldNotOwned = fmt.Errorf(
+		"%w: managed field not owned by expected manager",
+		api.ErrFailure,
+	)
+	// ErrWarningNotFound is returned when no warning returned by the
+	// Kubernetes API server contained a string in
+	// `assert.warnings.contains`.
+	ErrWarningNotFound = fmt.Errorf(
+		"%w: warning not found",
+		api.ErrFailure,
+	)
+	// ErrUnexpectedWarning is returned when the Kubernetes API server
+	// returned a warning and `assert.warnings.none` was set.
+	ErrUnexpectedWarning = fmt.Errorf(
+		"%w: unexpected warning",
+		api.ErrFailure,
+	)
+	// ErrWarningCountNotEqual is returned when the number of warnings
+	// returned by the Kubernetes API server was not `assert.warnings.count`.
+	ErrWarningCountNotEqual = fmt.Errorf(
+		"%w: warning count not equal",
+		api.ErrFailure,
+	)
+	// ErrDeprecationWarning is returned when the Kubernetes API server
+	// returned a deprecation warning and `fail-on-deprecation` was set.
+	ErrDeprecationWarning = fmt.Errorf(
+		"%w: deprecation warning",
+		api.ErrFailure,
+	)
+	// ErrPlacementNotSpread is returned when the Pods of an object were not
+	// evenly spread across the domains of an `assert.placement.spread`
+	// topology key.
+	ErrPlacementNotSpread = fmt.Errorf(
+		"%w: pods not spread",
+		api.ErrFailure,
+	)
+	// ErrPlacementNotPacked is returned when the Pods of an object landed in
+	// more topology domains than needed with `assert.placement.pack`.
+	ErrPlacementNotPacked = fmt.Errorf(
+		"%w: pods not packed",
+		api.ErrFailure,
+	)
+	// ErrPlacementViolated is returned when a Pod landed on a node that
+	// violates an `assert.placement` node selector, taint, affinity,
+	// anti-affinity or max-pods-per-node rule.
+	ErrPlacementViolated = fmt.Errorf(
+		"%w: placement rule violated",
+		api.ErrFailure,
+	)
+	// ErrConditionsNoItem is returned when no object in a list satisfied
+	// the `any` conditions of `assert.conditions`.
+	ErrConditionsNoItem = fmt.Errorf(
+		"%w: no list item satisfied the conditions",
+		api.ErrFailure,
+	)
+	// ErrLenNotInRange is returned when the number of items in a response
+	// was not between the `min` and `max` of `assert.len`.
+	ErrLenNotInRange = fmt.Errorf(
+		"%w: length not in range",
+		api.ErrFailure,
+	)
+	// ErrAggregateNotInRange is returned when the count or sum of an
+	// `assert.aggregate` was not the expected value.
+	ErrAggregateNotInRange = fmt.Errorf(
+		"%w: aggregate not in range",
+		api.ErrFailure,
+	)
+	// ErrNotReady is returned when the computed status of an object was not
+	// the status expected by `assert.ready`.
+	ErrNotReady = fmt.Errorf(
+		"%w: unexpected ready status",
+		api.ErrFailure,
+	)
+	// ErrCELFailed is returned when an `assert.cel` expression did not
+	// evaluate to true.
+	ErrCELFailed = fmt.Errorf(
+		"%w: CEL expression not true",
+		api.ErrFailure,
+	)
+	// ErrSnapshotMismatch is returned when the returned objects were not the
+	// same as the objects in an `assert.snapshot` golden file.
+	ErrSnapshotMismatch = fmt.Errorf(
+		"%w: objects did not match snapshot",
+		api.ErrFailure,
+	)
 	// ErrConnect is returned when we failed to create a client config to
 	// connect to the Kubernetes API server.
 	ErrConnect = fmt.Errorf(
@@ -64,12 +273,365 @@ func MatchesNotEqual(msg string) error {
 	return fmt.Errorf("%w: %s", ErrMatchesNotEqual, msg)
 }
 
+// MatchesObjectNotIdentified returns ErrMatchesObjectNotIdentified when a
+// `kube.assert.matches` object did not identify a single created or applied
+// object.
+func MatchesObjectNotIdentified(msg string) error {
+	return fmt.Errorf("%w: %s", ErrMatchesObjectNotIdentified, msg)
+}
+
+// MatchesNoItem returns ErrMatchesNoItem along with the differences between
+// each list item and the `kube.assert.matches.any` object.
+func MatchesNoItem(count int, itemDiffs []string) error {
+	if count == 0 {
+		return fmt.Errorf("%w: the list is empty", ErrMatchesNoItem)
+	}
+	return fmt.Errorf(
+		"%w: %s", ErrMatchesNoItem, strings.Join(itemDiffs, "; "),
+	)
+}
+
+// MatchesItemMatched returns ErrMatchesItemMatched for the supplied list
+// item.
+func MatchesItemMatched(item string) error {
+	return fmt.Errorf("%w: %s", ErrMatchesItemMatched, item)
+}
+
+// MatchesItemsNotEqual returns ErrMatchesItemsNotEqual along with the names
+// of the missing and unexpected list items.
+func MatchesItemsNotEqual(missing []string, unexpected []string) error {
+	msgs := []string{}
+	if len(missing) > 0 {
+		msgs = append(msgs, "missing "+strings.Join(missing, ", "))
+	}
+	if len(unexpected) > 0 {
+		msgs = append(msgs, "unexpected "+strings.Join(unexpected, ", "))
+	}
+	return fmt.Errorf(
+		"%w: %s", ErrMatchesItemsNotEqual, strings.Join(msgs, "; "),
+	)
+}
+
 // ConditionDoesNotMatch returns ErrConditionDoesNotMatch when a
 // `kube.assert.conditions` object did not match the returned resource.
 func ConditionDoesNotMatch(msg string) error {
 	return fmt.Errorf("%w: %s", ErrConditionDoesNotMatch, msg)
 }
 
+// RegexNotMatched returns ErrRegexNotMatched when a regular expression did not
+// match the contents of the named pipe or logs.
+func RegexNotMatched(expr string, name string) error {
+	return fmt.Errorf("%w: expected %s to match %q", ErrRegexNotMatched, name, expr)
+}
+
+// WaitNotConverged returns ErrWaitNotConverged for the supplied object along
+// with the last observed state of that object.
+func WaitNotConverged(resource string, name string, observed string) error {
+	return fmt.Errorf(
+		"%w: %s/%s: last observed: %s",
+		ErrWaitNotConverged, resource, name, observed,
+	)
+}
+
+// WatchUntilNotReached returns ErrWatchUntilNotReached along with the number
+// of events that were observed.
+func WatchUntilNotReached(observed int) error {
+	return fmt.Errorf(
+		"%w: observed %d event(s)", ErrWatchUntilNotReached, observed,
+	)
+}
+
+// WatchDurationNotElapsed returns ErrWatchDurationNotElapsed for the supplied
+// `kube.watch.duration` and the time the test spec's timeout left for the
+// watch.
+func WatchDurationNotElapsed(duration string, available time.Duration) error {
+	return fmt.Errorf(
+		"%w: the test spec's timeout left %s to watch for %s. increase "+
+			"the test spec's timeout",
+		ErrWatchDurationNotElapsed, available.Round(time.Millisecond),
+		duration,
+	)
+}
+
+// WatchSequenceNotMatched returns ErrWatchSequenceNotMatched for the supplied
+// sequence step.
+func WatchSequenceNotMatched(step int, from int, observed int) error {
+	return fmt.Errorf(
+		"%w: step %d not matched by any of events %d-%d",
+		ErrWatchSequenceNotMatched, step, from, observed-1,
+	)
+}
+
+// WatchNeverMatched returns ErrWatchNeverMatched for the supplied never
+// fragment and the event that matched it.
+func WatchNeverMatched(
+	fragment int,
+	event int,
+	eventType string,
+	name string,
+) error {
+	return fmt.Errorf(
+		"%w: never[%d] matched event %d (%s %s)",
+		ErrWatchNeverMatched, fragment, event, eventType, name,
+	)
+}
+
+// WatchEventCountNotEqual returns ErrWatchEventCountNotEqual for the supplied
+// event type.
+func WatchEventCountNotEqual(eventType string, exp int, got int) error {
+	return fmt.Errorf(
+		"%w: expected %d %s event(s) but got %d",
+		ErrWatchEventCountNotEqual, exp, eventType, got,
+	)
+}
+
+// EventReasonNotFound returns ErrEventReasonNotFound for the supplied reason
+// along with the reasons of the Events that were found.
+func EventReasonNotFound(reason string, found []string) error {
+	if len(found) == 0 {
+		return fmt.Errorf(
+			"%w: expected %q but found no events",
+			ErrEventReasonNotFound, reason,
+		)
+	}
+	return fmt.Errorf(
+		"%w: expected %q but found %s",
+		ErrEventReasonNotFound, reason, strings.Join(found, ", "),
+	)
+}
+
+// UnexpectedWarningEvent returns ErrUnexpectedWarningEvent for the supplied
+// Warning Event reason and message.
+func UnexpectedWarningEvent(reason string, message string) error {
+	return fmt.Errorf(
+		"%w: %s: %s", ErrUnexpectedWarningEvent, reason, message,
+	)
+}
+
+// EventCountTooLow returns ErrEventCountTooLow along with the number of
+// occurrences that were found.
+func EventCountTooLow(exp int, got int64) error {
+	return fmt.Errorf(
+		"%w: expected at least %d occurrence(s) but got %d",
+		ErrEventCountTooLow, exp, got,
+	)
+}
+
+// AssertionUnsupported returns ErrAssertionUnsupported for the supplied
+// assertion field and a description of the subject it cannot be made about.
+func AssertionUnsupported(field string, subject string) error {
+	return fmt.Errorf(
+		"%w: assert.%s is not supported for %s",
+		ErrAssertionUnsupported, field, subject,
+	)
+}
+
+// ExpectedStatus returns ErrExpectedStatus along with what happened instead.
+func ExpectedStatus(msg string) error {
+	return fmt.Errorf("%w: %s", ErrExpectedStatus, msg)
+}
+
+// StatusDoesNotMatch returns ErrStatusDoesNotMatch when the Status returned
+// by the Kubernetes API server did not match the `kube.assert.status` object.
+func StatusDoesNotMatch(msg string) error {
+	return fmt.Errorf("%w: %s", ErrStatusDoesNotMatch, msg)
+}
+
+// ExpectedConflict returns ErrExpectedConflict along with what happened
+// instead.
+func ExpectedConflict(msg string) error {
+	return fmt.Errorf("%w: %s", ErrExpectedConflict, msg)
+}
+
+// ConflictDoesNotMatch returns ErrConflictDoesNotMatch when a field manager
+// conflict did not match the `kube.assert.conflict` object.
+func ConflictDoesNotMatch(msg string) error {
+	return fmt.Errorf("%w: %s", ErrConflictDoesNotMatch, msg)
+}
+
+// ManagedFieldNotOwned returns ErrManagedFieldNotOwned for the supplied field
+// path along with the field managers that own the field.
+func ManagedFieldNotOwned(path string, exp string, owners []string) error {
+	if len(owners) == 0 {
+		return fmt.Errorf(
+			"%w: expected %s to be owned by %q but it has no owner",
+			ErrManagedFieldNotOwned, path, exp,
+		)
+	}
+	return fmt.Errorf(
+		"%w: expected %s to be owned by %q but it is owned by %s",
+		ErrManagedFieldNotOwned, path, exp, strings.Join(owners, ", "),
+	)
+}
+
+// WarningNotFound returns ErrWarningNotFound for the supplied expected
+// string along with the warnings that were returned.
+func WarningNotFound(exp string, found []string) error {
+	if len(found) == 0 {
+		return fmt.Errorf(
+			"%w: expected %q but found no warnings",
+			ErrWarningNotFound, exp,
+		)
+	}
+	return fmt.Errorf(
+		"%w: expected %q but found %s",
+		ErrWarningNotFound, exp, strings.Join(found, "; "),
+	)
+}
+
+// UnexpectedWarning returns ErrUnexpectedWarning for the supplied warning
+// text.
+func UnexpectedWarning(text string) error {
+	return fmt.Errorf("%w: %s", ErrUnexpectedWarning, text)
+}
+
+// WarningCountNotEqual returns ErrWarningCountNotEqual along with the
+// warnings that were returned.
+func WarningCountNotEqual(exp int, found []string) error {
+	if len(found) == 0 {
+		return fmt.Errorf(
+			"%w: expected %d but found no warnings",
+			ErrWarningCountNotEqual, exp,
+		)
+	}
+	return fmt.Errorf(
+		"%w: expected %d but found %d: %s",
+		ErrWarningCountNotEqual, exp, len(found), strings.Join(found, "; "),
+	)
+}
+
+// DeprecationWarning returns ErrDeprecationWarning for the supplied warning
+// text.
+func DeprecationWarning(text string) error {
+	return fmt.Errorf("%w: %s", ErrDeprecationWarning, text)
+}
+
+// PlacementNotSpread returns ErrPlacementNotSpread for the supplied topology
+// key along with the skew found, the maximum skew and the number of Pods in
+// each domain.
+func PlacementNotSpread(
+	topoKey string,
+	skew int,
+	maxSkew int,
+	counts map[string]int,
+) error {
+	return fmt.Errorf(
+		"%w: found skew of %d but expected at most %d for topology key %s "+
+			"with pods per domain: %s",
+		ErrPlacementNotSpread, skew, maxSkew, topoKey,
+		domainCountsString(counts),
+	)
+}
+
+// PlacementNotPacked returns ErrPlacementNotPacked for the supplied topology
+// key along with the expected number of domains and the number of Pods in
+// each domain.
+func PlacementNotPacked(topoKey string, exp int, counts map[string]int) error {
+	return fmt.Errorf(
+		"%w: expected pods in %d domain(s) for topology key %s but "+
+			"found pods per domain: %s",
+		ErrPlacementNotPacked, exp, topoKey, domainCountsString(counts),
+	)
+}
+
+// PlacementViolated returns ErrPlacementViolated for the supplied Pod, the
+// node it landed on and the violated placement rule.
+func PlacementViolated(pod string, node string, rule string) error {
+	return fmt.Errorf(
+		"%w: pod %s on node %s violates %s",
+		ErrPlacementViolated, pod, node, rule,
+	)
+}
+
+// ConditionsNoItem returns ErrConditionsNoItem along with the differences
+// between each item's Conditions and the expected Conditions.
+func ConditionsNoItem(count int, itemDiffs []string) error {
+	if count == 0 {
+		return fmt.Errorf("%w: the list is empty", ErrConditionsNoItem)
+	}
+	return fmt.Errorf(
+		"%w: %s", ErrConditionsNoItem, strings.Join(itemDiffs, "; "),
+	)
+}
+
+// LenNotInRange returns ErrLenNotInRange along with the expected range and
+// the number of items found.
+func LenNotInRange(minimum *int, maximum *int, got int) error {
+	minStr, maxStr := "", ""
+	if minimum != nil {
+		minStr = strconv.Itoa(*minimum)
+	}
+	if maximum != nil {
+		maxStr = strconv.Itoa(*maximum)
+	}
+	return fmt.Errorf(
+		"%w: expected %s items but found %d",
+		ErrLenNotInRange, rangeString(minStr, maxStr), got,
+	)
+}
+
+// AggregateNotInRange returns ErrAggregateNotInRange for the supplied
+// aggregate description, the expected value and what was found instead.
+func AggregateNotInRange(agg string, exp string, got string) error {
+	return fmt.Errorf(
+		"%w: expected %s to be %s but found %s",
+		ErrAggregateNotInRange, agg, exp, got,
+	)
+}
+
+// AggregateNotNumeric returns ErrAggregateNotInRange when the JSONPath
+// expression of an `assert.aggregate` sum selected a value that is not a
+// number or resource quantity.
+func AggregateNotNumeric(path string, value any) error {
+	return fmt.Errorf(
+		"%w: %s selected %v, which is not a number or resource quantity",
+		ErrAggregateNotInRange, path, value,
+	)
+}
+
+// NotReady returns ErrNotReady for the supplied object, the expected
+// status, the computed status and the message explaining it.
+func NotReady(obj string, exp string, status string, msg string) error {
+	return fmt.Errorf(
+		"%w: %s: expected %s but found %s: %s",
+		ErrNotReady, obj, exp, status, msg,
+	)
+}
+
+// CELFailed returns ErrCELFailed for the supplied CEL expression, the reason
+// it was not true and the values of the fields it references.
+func CELFailed(expr string, reason string, refs []string) error {
+	if len(refs) == 0 {
+		return fmt.Errorf("%w: %s: %s", ErrCELFailed, expr, reason)
+	}
+	return fmt.Errorf(
+		"%w: %s: %s where %s",
+		ErrCELFailed, expr, reason, strings.Join(refs, ", "),
+	)
+}
+
+// SnapshotMismatch returns ErrSnapshotMismatch for the supplied golden file
+// and the unified diff between it and the returned objects.
+func SnapshotMismatch(path string, diff string) error {
+	return fmt.Errorf("%w: %s:\n%s", ErrSnapshotMismatch, path, diff)
+}
+
+// SnapshotNotFound returns ErrSnapshotMismatch when the supplied golden file
+// does not exist.
+func SnapshotNotFound(path string) error {
+	return fmt.Errorf(
+		"%w: %s does not exist. set %s=1 to create it",
+		ErrSnapshotMismatch, path, UpdateSnapshotsEnvVar,
+	)
+}
+
+// Diff returns the supplied failure for the supplied subject, e.g.
+// "Deployment/nginx", along with a diff between what was expected and what
+// was found.
+func Diff(err error, subject string, diff string) error {
+	return fmt.Errorf("%w: %s:\n%s", err, subject, diff)
+}
+
 // ConnectError returns ErrConnnect when an error is found trying to construct
 // a Kubernetes client connection.
 func ConnectError(err error) error {
@@ -25,14 +25,25 @@ func (s *Spec) Eval(ctx context.Context) (*api.Result, error) {
 	}
 
 	ns := s.Namespace()
-	nsCreated, err := ensureNamespace(ctx, c, ns)
-	if err != nil {
-		return nil, err
-	}
-	if nsCreated {
-		debug.Printf(ctx, "auto-created namespace: %s", ns)
+	nsCreated := false
+	if s.DryRun() == DryRunServer {
+		// A server-side dry-run must not change the cluster, so the namespace
+		// is not auto-created.
+		debug.Printf(ctx, "dry-run: skipped auto-creating namespace: %s", ns)
+		s.Kube.dryRun = []string{metav1.DryRunAll}
+	} else {
+		nsCreated, err = ensureNamespace(ctx, c, ns)
+		if err != nil {
+			return nil, err
+		}
+		if nsCreated {
+			debug.Printf(ctx, "auto-created namespace: %s", ns)
+		}
 	}
+	s.Kube.fieldManager = s.Kube.FieldManager
+	s.Kube.force = s.Kube.Force
 
+	c.warnings.reset()
 	var out any
 	err = s.Kube.Do(ctx, c, ns, &out)
 	if err != nil {
@@ -43,7 +54,7 @@ func (s *Spec) Eval(ctx context.Context) (*api.Result, error) {
 			return nil, err
 		}
 	}
-	a := newAssertions(c, s.Assert, err, out)
+	a := newAssertions(c, s.Assert, err, out, s.FailOnDeprecation())
 	if a.OK(ctx) {
 		res := api.NewResult()
 		if nsCreated {
@@ -0,0 +1,344 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/gdt-dev/core/debug"
+	"github.com/samber/lo"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// EventTypeNormal is the type of Events that record normal operations
+	EventTypeNormal = "Normal"
+	// EventTypeWarning is the type of Events that record something
+	// unexpected
+	EventTypeWarning = "Warning"
+)
+
+var (
+	// coreEventsResource is the core/v1 Events resource
+	coreEventsResource = schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "events",
+	}
+	// eventsV1Resource is the events.k8s.io/v1 Events resource
+	eventsV1Resource = schema.GroupVersionResource{
+		Group:    "events.k8s.io",
+		Version:  "v1",
+		Resource: "events",
+	}
+)
+
+// EventsAction describes the Events to fetch for one or more objects
+type EventsAction struct {
+	// Target is a string or object identifying the object(s) whose Events
+	// should be fetched. It takes the same forms as the argument to
+	// `kube.get`:
+	//
+	// - a string with a resource kind or kind alias, e.g. "pods", "po",
+	//   optionally followed by a space or `/` character followed by the
+	//   resource name. Without a name, Events involving any object of that
+	//   kind are fetched.
+	// - an object with a `type` and optional `name` and `labels` fields
+	//   containing a label selector that should be used to select that `type`
+	//   of resource.
+	Target *ResourceIdentifier `yaml:"target"`
+	// Since is a duration string (e.g. "5m") limiting the Events to those
+	// that last occurred more recently than the duration. This prevents old
+	// Events, e.g. from a previous test run, from satisfying assertions.
+	Since string `yaml:"since,omitempty"`
+}
+
+// Title returns the events target's kind and name, if present
+func (e *EventsAction) Title() string {
+	if e.Target == nil {
+		return ""
+	}
+	return e.Target.Title()
+}
+
+// NewEventsAction returns a new EventsAction for the supplied target and
+// `since` duration.
+func NewEventsAction(
+	target *ResourceIdentifier,
+	since string,
+) *EventsAction {
+	return &EventsAction{
+		Target: target,
+		Since:  since,
+	}
+}
+
+// eventRecord is an Event normalized from either the core/v1 or the
+// events.k8s.io/v1 representation.
+type eventRecord struct {
+	// apiVersion is the API version the Event was fetched from
+	apiVersion string
+	// typ is the Event type, e.g. "Normal" or "Warning"
+	typ string
+	// reason is the Event's short, machine-understandable reason
+	reason string
+	// message is the Event's human-readable message (or note)
+	message string
+	// count is the number of times the Event occurred
+	count int64
+	// timestamp is the last time the Event occurred
+	timestamp time.Time
+	// involvedObject is the object the Event is about
+	involvedObject map[string]any
+	// reportingController is the controller that emitted the Event
+	reportingController string
+}
+
+// asMap returns the normalized Event as a map[string]any
+func (r *eventRecord) asMap() map[string]any {
+	return map[string]any{
+		"apiVersion":          r.apiVersion,
+		"type":                r.typ,
+		"reason":              r.reason,
+		"message":             r.message,
+		"count":               r.count,
+		"timestamp":           r.timestamp.UTC().Format(time.RFC3339),
+		"involvedObject":      r.involvedObject,
+		"reportingController": r.reportingController,
+	}
+}
+
+// eventRecordFromObject normalizes the supplied core/v1 or events.k8s.io/v1
+// Event.
+func eventRecordFromObject(obj *unstructured.Unstructured) *eventRecord {
+	content := obj.Object
+	r := &eventRecord{
+		apiVersion: obj.GetAPIVersion(),
+		timestamp:  eventTimestamp(obj),
+	}
+	r.typ, _, _ = unstructured.NestedString(content, "type")
+	r.reason, _, _ = unstructured.NestedString(content, "reason")
+	if obj.GetAPIVersion() == eventsV1Resource.GroupVersion().String() {
+		r.message, _, _ = unstructured.NestedString(content, "note")
+		r.involvedObject, _, _ = unstructured.NestedMap(content, "regarding")
+		r.reportingController, _, _ = unstructured.NestedString(
+			content, "reportingController",
+		)
+		r.count, _, _ = unstructured.NestedInt64(content, "series", "count")
+		if r.count == 0 {
+			r.count, _, _ = unstructured.NestedInt64(content, "deprecatedCount")
+		}
+	} else {
+		r.message, _, _ = unstructured.NestedString(content, "message")
+		r.involvedObject, _, _ = unstructured.NestedMap(
+			content, "involvedObject",
+		)
+		r.reportingController, _, _ = unstructured.NestedString(
+			content, "reportingComponent",
+		)
+		if r.reportingController == "" {
+			r.reportingController, _, _ = unstructured.NestedString(
+				content, "source", "component",
+			)
+		}
+		r.count, _, _ = unstructured.NestedInt64(content, "count")
+	}
+	if r.count == 0 {
+		r.count = 1
+	}
+	return r
+}
+
+// eventsOutput contains the Events fetched by `kube.events`
+type eventsOutput struct {
+	// events contains the normalized Events, oldest first
+	events []*eventRecord
+}
+
+// items returns the normalized Events as a slice of any suitable for JSONPath
+// lookups.
+func (o *eventsOutput) items() []any {
+	res := make([]any, len(o.events))
+	for x, ev := range o.events {
+		res[x] = ev.asMap()
+	}
+	return res
+}
+
+// events fetches the core/v1 and events.k8s.io/v1 Events involving the
+// targeted object(s) and populates `out` with an `*eventsOutput`.
+func (a *Action) events(
+	ctx context.Context,
+	c *connection,
+	ns string,
+	out *interface{},
+) error {
+	target := a.Events.Target
+	arg := target.Arg
+	argRep := gdtcontext.ReplaceVariables(ctx, arg)
+	if arg != argRep {
+		debug.Printf(
+			ctx,
+			"kube.events: replaced arg: %s -> %s",
+			arg, argRep,
+		)
+	}
+	name := target.Name
+	nameRep := gdtcontext.ReplaceVariables(ctx, name)
+	if name != nameRep {
+		debug.Printf(
+			ctx,
+			"kube.events: replaced name: %s -> %s",
+			name, nameRep,
+		)
+	}
+	mapping, err := c.mappingForArg(argRep)
+	if err != nil {
+		return err
+	}
+	kind := mapping.GroupVersionKind.Kind
+
+	// involves returns true if the supplied involvedObject refers to one of
+	// the targeted objects.
+	var involves func(obj map[string]any) bool
+	switch {
+	case nameRep != "":
+		// NOTE: We match on kind and name instead of UID so that Events for
+		// an object that has since been deleted and recreated are found.
+		involves = func(obj map[string]any) bool {
+			return obj["kind"] == kind && obj["name"] == nameRep
+		}
+	case target.Labels != nil:
+		list, err := a.doList(ctx, c, mapping.Resource, ns, target.Labels)
+		if err != nil {
+			return err
+		}
+		uids := map[types.UID]bool{}
+		for _, item := range list.Items {
+			uids[item.GetUID()] = true
+		}
+		involves = func(obj map[string]any) bool {
+			uid, _ := obj["uid"].(string)
+			return uids[types.UID(uid)]
+		}
+	default:
+		involves = func(obj map[string]any) bool {
+			return obj["kind"] == kind
+		}
+	}
+
+	var since time.Time
+	if a.Events.Since != "" {
+		// We validated the duration during parse
+		d, _ := time.ParseDuration(a.Events.Since)
+		since = time.Now().Add(-d)
+	}
+
+	res := &eventsOutput{}
+	seen := map[types.UID]bool{}
+	for _, gvr := range []schema.GroupVersionResource{
+		coreEventsResource, eventsV1Resource,
+	} {
+		debug.Printf(
+			ctx, "kube.events: %s (ns: %s, kind: %s, name: %q)",
+			gvr.GroupVersion(), ns, kind, nameRep,
+		)
+		list, err := c.client.Resource(gvr).Namespace(ns).List(
+			ctx, metav1.ListOptions{},
+		)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				// The cluster does not serve this Events API version
+				continue
+			}
+			return err
+		}
+		for x := range list.Items {
+			item := &list.Items[x]
+			// Both APIs are views of the same Event objects, so we skip any
+			// Event we have already seen via the other API.
+			if seen[item.GetUID()] {
+				continue
+			}
+			seen[item.GetUID()] = true
+			ev := eventRecordFromObject(item)
+			if !involves(ev.involvedObject) {
+				continue
+			}
+			if !since.IsZero() && ev.timestamp.Before(since) {
+				continue
+			}
+			res.events = append(res.events, ev)
+		}
+	}
+	sort.SliceStable(res.events, func(i, j int) bool {
+		return res.events[i].timestamp.Before(res.events[j].timestamp)
+	})
+	debug.Printf(ctx, "kube.events: found %d event(s)", len(res.events))
+	*out = res
+	return nil
+}
+
+// eventsOK returns true if the subject is the output of a `kube.events`
+// action and matches the Events conditions, false otherwise.
+func (a *assertions) eventsOK(ctx context.Context) bool {
+	exp := a.exp
+	out, ok := a.r.(*eventsOutput)
+	if !ok || out == nil || exp.Events == nil {
+		return true
+	}
+	ee := exp.Events
+	res := true
+	if ee.NoWarnings {
+		for _, ev := range out.events {
+			if ev.typ == EventTypeWarning {
+				a.Fail(UnexpectedWarningEvent(ev.reason, ev.message))
+				res = false
+			}
+		}
+	}
+	events := out.events
+	if ee.Type != "" {
+		events = lo.Filter(events, func(ev *eventRecord, _ int) bool {
+			return strings.EqualFold(ev.typ, ee.Type)
+		})
+	}
+	var expReasons []string
+	if ee.HasReason != nil {
+		expReasons = replaceVariablesInStrings(ctx, ee.HasReason.Values())
+		reasons := lo.Uniq(lo.Map(events, func(ev *eventRecord, _ int) string {
+			return ev.reason
+		}))
+		for _, reason := range expReasons {
+			if !lo.Contains(reasons, reason) {
+				a.Fail(EventReasonNotFound(reason, reasons))
+				res = false
+			}
+		}
+	}
+	if ee.Count != nil {
+		// When has-reason is set, only the occurrences of Events having one
+		// of those reasons are counted.
+		total := int64(0)
+		for _, ev := range events {
+			if expReasons != nil && !lo.Contains(expReasons, ev.reason) {
+				continue
+			}
+			total += ev.count
+		}
+		if total < int64(*ee.Count) {
+			a.Fail(EventCountTooLow(*ee.Count, total))
+			res = false
+		}
+	}
+	return res
+}
@@ -0,0 +1,296 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gdt-dev/core/api"
+	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/gdt-dev/core/debug"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// ExecAction describes a command that will be executed in a container of a
+// Pod, similar to `kubectl exec`.
+type ExecAction struct {
+	// Target is a string or object identifying the Pod to execute the command
+	// in. It must be one of the following:
+	//
+	// - a string with the name of a Pod, e.g. "nginx"
+	// - a string with a resource kind or kind alias followed by a `/`
+	//   character and the resource name, e.g. "pods/nginx" or
+	//   "deployments/nginx". When the resource is a workload like a
+	//   Deployment, the workload's `spec.selector` is used to find a running
+	//   Pod.
+	// - an object with a `type` and optional `name` and `labels` fields. The
+	//   first running Pod selected is used.
+	Target *ResourceIdentifier `yaml:"target"`
+	// Container is the name of the container to execute the command in. If
+	// empty, the container named in the Pod's
+	// `kubectl.kubernetes.io/default-container` annotation is used, falling
+	// back to the Pod's first container.
+	Container string `yaml:"container,omitempty"`
+	// Command is the command and arguments to execute. It may be either a
+	// string, which is split into arguments using shell-style quoting rules,
+	// or a list of strings.
+	Command []string `yaml:"command"`
+}
+
+// Title returns the exec target's kind and name, if present
+func (e *ExecAction) Title() string {
+	if e.Target == nil {
+		return ""
+	}
+	return e.Target.Title()
+}
+
+// NewExecAction returns a new ExecAction running the supplied command in the
+// supplied container of the target.
+func NewExecAction(
+	target *ResourceIdentifier,
+	container string,
+	command []string,
+) *ExecAction {
+	return &ExecAction{
+		Target:    target,
+		Container: container,
+		Command:   command,
+	}
+}
+
+// execOutput contains the results of executing a command in a container.
+type execOutput struct {
+	// pod is the name of the Pod the command was executed in
+	pod string
+	// container is the name of the container the command was executed in
+	container string
+	// stdout contains the command's standard output
+	stdout *bytes.Buffer
+	// stderr contains the command's standard error
+	stderr *bytes.Buffer
+	// exitCode is the command's exit code
+	exitCode int
+}
+
+// asMap returns the exec output as a map[string]any suitable for JSONPath
+// lookups when saving variables.
+func (o *execOutput) asMap() map[string]any {
+	return map[string]any{
+		"pod":       o.pod,
+		"container": o.container,
+		"stdout":    strings.TrimSpace(o.stdout.String()),
+		"stderr":    strings.TrimSpace(o.stderr.String()),
+		"exitCode":  o.exitCode,
+	}
+}
+
+// exec executes a command in a container of a Pod and populates `out` with
+// an `*execOutput`. A non-zero exit code from the command is not considered
+// an error; it is recorded in the output and evaluated by the assertions.
+func (a *Action) exec(
+	ctx context.Context,
+	c *connection,
+	ns string,
+	out *interface{},
+) error {
+	pods, err := podsForTarget(ctx, c, ns, a.Exec.Target)
+	if err != nil {
+		return err
+	}
+	pod := firstRunningPod(pods)
+	if pod == nil {
+		return fmt.Errorf(
+			"no running pod found for %s", a.Exec.Target.Title(),
+		)
+	}
+	container := a.Exec.Container
+	if container == "" {
+		container = defaultContainerName(pod)
+	}
+	cmd := make([]string, len(a.Exec.Command))
+	for x, arg := range a.Exec.Command {
+		argRep := gdtcontext.ReplaceVariables(ctx, arg)
+		if arg != argRep {
+			debug.Printf(
+				ctx,
+				"kube.exec: replaced arg: %s -> %s",
+				arg, argRep,
+			)
+		}
+		cmd[x] = argRep
+	}
+
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   cmd,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	spdyExec, err := remotecommand.NewSPDYExecutor(c.cfg, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	wsExec, err := remotecommand.NewWebSocketExecutor(
+		c.cfg, "GET", req.URL().String(),
+	)
+	if err != nil {
+		return err
+	}
+	executor, err := remotecommand.NewFallbackExecutor(
+		wsExec, spdyExec, func(err error) bool {
+			return httpstream.IsUpgradeFailure(err) ||
+				httpstream.IsHTTPSProxyError(err)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	debug.Printf(
+		ctx, "kube.exec: %s (pod: %s, container: %s, ns: %s)",
+		strings.Join(cmd, " "), pod.Name, container, pod.Namespace,
+	)
+	res := &execOutput{
+		pod:       pod.Name,
+		container: container,
+		stdout:    &bytes.Buffer{},
+		stderr:    &bytes.Buffer{},
+	}
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: res.stdout,
+		Stderr: res.stderr,
+	})
+	if err != nil {
+		var exitErr utilexec.ExitError
+		if !errors.As(err, &exitErr) {
+			return err
+		}
+		res.exitCode = exitErr.ExitStatus()
+	}
+	debug.Printf(ctx, "kube.exec: exit code: %d", res.exitCode)
+	*out = res
+	return nil
+}
+
+// execOK returns true if the subject is the output of a `kube.exec` command
+// and matches the ExitCode, Out and Err conditions, false otherwise.
+func (a *assertions) execOK(ctx context.Context) bool {
+	exp := a.exp
+	out, ok := a.r.(*execOutput)
+	if !ok || out == nil {
+		return true
+	}
+	res := true
+	if exp.ExitCode != out.exitCode {
+		a.Fail(api.NotEqual(exp.ExitCode, out.exitCode))
+		res = false
+	}
+	if !a.pipeOK(ctx, exp.Out, "stdout", out.stdout.String()) {
+		res = false
+	}
+	if !a.pipeOK(ctx, exp.Err, "stderr", out.stderr.String()) {
+		res = false
+	}
+	return res
+}
+
+// pipeOK returns true if the supplied pipe contents match the PipeExpect
+// conditions, false otherwise.
+func (a *assertions) pipeOK(
+	ctx context.Context,
+	exp *PipeExpect,
+	name string,
+	contents string,
+) bool {
+	if exp == nil {
+		return true
+	}
+	res := true
+	contents = strings.TrimSpace(contents)
+	if exp.ContainsAll != nil {
+		for _, find := range replaceVariablesInStrings(ctx, exp.ContainsAll.Values()) {
+			if !strings.Contains(contents, find) {
+				a.Fail(api.NotIn(find, name))
+				res = false
+			}
+		}
+	}
+	if exp.ContainsAny != nil {
+		vals := replaceVariablesInStrings(ctx, exp.ContainsAny.Values())
+		found := false
+		for _, find := range vals {
+			if strings.Contains(contents, find) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a.Fail(api.NoneIn(vals, name))
+			res = false
+		}
+	}
+	if exp.ContainsNone != nil {
+		for _, find := range replaceVariablesInStrings(ctx, exp.ContainsNone.Values()) {
+			if strings.Contains(contents, find) {
+				a.Fail(api.In(find, name))
+				res = false
+			}
+		}
+	}
+	if exp.Regex != nil {
+		for _, expr := range replaceVariablesInStrings(ctx, exp.Regex.Values()) {
+			// We validated the regular expression during parse
+			re := regexp.MustCompile(expr)
+			if !re.MatchString(contents) {
+				a.Fail(RegexNotMatched(expr, name))
+				res = false
+			}
+		}
+	}
+	if exp.Lines != nil {
+		lines := 0
+		if contents != "" {
+			lines = len(strings.Split(contents, "\n"))
+		}
+		if *exp.Lines != lines {
+			a.Fail(api.NotEqualLength(*exp.Lines, lines))
+			res = false
+		}
+	}
+	return res
+}
+
+// replaceVariablesInStrings returns a copy of the supplied strings with any
+// variable references replaced with the variable values from stored run data
+func replaceVariablesInStrings(ctx context.Context, vals []string) []string {
+	return lo.Map(vals, func(val string, _ int) string {
+		valRep := gdtcontext.ReplaceVariables(ctx, val)
+		if val != valRep {
+			debug.Printf(
+				ctx,
+				"kube.assert: replaced var: %s -> %s",
+				val, valRep,
+			)
+		}
+		return valRep
+	})
+}
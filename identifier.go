@@ -38,6 +38,8 @@ func (r *ResourceIdentifier) Title() string {
 	return r.Arg + "/" + r.Name
 }
 
+// NewResourceIdentifier returns a new ResourceIdentifier for the supplied
+// resource arg, name and label selector.
 func NewResourceIdentifier(
 	arg string,
 	name string,
@@ -77,6 +79,8 @@ func (r *ResourceIdentifierOrFile) Title() string {
 	return r.Arg + "/" + r.Name
 }
 
+// NewResourceIdentifierOrFile returns a new ResourceIdentifierOrFile for the
+// supplied filepath, resource arg, name and label selector.
 func NewResourceIdentifierOrFile(
 	fp string,
 	arg string,
@@ -0,0 +1,319 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+const (
+	// listDirectiveSubset is the `matches` list directive indicating that
+	// each item must match a different item of the subject's list, in any
+	// order. The subject's list may have other items.
+	listDirectiveSubset = "$subset"
+	// listDirectiveUnordered is the `matches` list directive indicating that
+	// each item must match a different item of the subject's list, in any
+	// order, and that the lists must have the same length.
+	listDirectiveUnordered = "$unordered"
+	// listDirectiveMerge is the `matches` list directive indicating that each
+	// item must match the item of the subject's list with the same value of
+	// the list's patch merge key, e.g. `name` for a Pod's containers. The
+	// subject's list may have other items.
+	listDirectiveMerge = "$merge"
+	// listDirectiveMergeKey is the `matches` list directive field that sets
+	// the merge key used by `$merge`, e.g. for the lists of custom resources
+	// which have no known patch merge keys.
+	listDirectiveMergeKey = "$merge-key"
+)
+
+// listStrategies are the `matches` list directives that select how a list is
+// matched
+var listStrategies = []string{
+	listDirectiveSubset,
+	listDirectiveUnordered,
+	listDirectiveMerge,
+}
+
+// listDirective describes how the items of a `matches` list are matched
+// against the items of the subject's list.
+type listDirective struct {
+	// strategy is one of the listStrategies
+	strategy string
+	// items are the `matches` fragments for the list's items
+	items []any
+	// mergeKey is the `$merge-key` field, if any
+	mergeKey string
+}
+
+// listDirectiveFrom returns the list directive described by the supplied
+// `matches` map and true if the map is a list directive, e.g. a map with a
+// `$merge` field containing the list of items to match. An error is returned
+// when the map is a malformed list directive.
+func listDirectiveFrom(m map[string]any) (*listDirective, bool, error) {
+	isDirective := false
+	for k := range m {
+		if k == listDirectiveMergeKey || isListStrategy(k) {
+			isDirective = true
+			break
+		}
+	}
+	if !isDirective {
+		return nil, false, nil
+	}
+	ld := &listDirective{}
+	for k, v := range m {
+		switch {
+		case isListStrategy(k):
+			if ld.strategy != "" {
+				return nil, true, fmt.Errorf(
+					"only one of %s may be used",
+					strings.Join(listStrategies, ", "),
+				)
+			}
+			items, ok := v.([]any)
+			if !ok {
+				return nil, true, fmt.Errorf(
+					"%s must be a list but got %T", k, v,
+				)
+			}
+			ld.strategy = k
+			ld.items = items
+		case k == listDirectiveMergeKey:
+			key, ok := v.(string)
+			if !ok || key == "" {
+				return nil, true, fmt.Errorf(
+					"%s must be a field name", listDirectiveMergeKey,
+				)
+			}
+			ld.mergeKey = key
+		default:
+			return nil, true, fmt.Errorf(
+				"unexpected field %q in list directive", k,
+			)
+		}
+	}
+	if ld.strategy == "" {
+		return nil, true, fmt.Errorf(
+			"%s requires %s", listDirectiveMergeKey, listDirectiveMerge,
+		)
+	}
+	if ld.mergeKey != "" && ld.strategy != listDirectiveMerge {
+		return nil, true, fmt.Errorf(
+			"%s may only be used with %s",
+			listDirectiveMergeKey, listDirectiveMerge,
+		)
+	}
+	return ld, true, nil
+}
+
+// isListStrategy returns true if the supplied `matches` key is one of the
+// list strategy directives.
+func isListStrategy(k string) bool {
+	return lo.Contains(listStrategies, k)
+}
+
+// validateListDirectives returns an error if any list directive in the
+// supplied `matches` content is malformed.
+func validateListDirectives(match any) error {
+	switch match := match.(type) {
+	case map[string]any:
+		ld, isDirective, err := listDirectiveFrom(match)
+		if err != nil {
+			return err
+		}
+		if isDirective {
+			return validateListDirectives(ld.items)
+		}
+		for _, v := range match {
+			if err := validateListDirectives(v); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, v := range match {
+			if err := validateListDirectives(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// collectListDifferences compares the items of a `matches` list directive
+// against the supplied subject list and adds any differences to the supplied
+// set of differences.
+func collectListDifferences(
+	fp string,
+	ld *listDirective,
+	subject any,
+	meta strategicpatch.LookupPatchMeta,
+	mergeKey string,
+	delta *delta,
+) {
+	subjectlist, ok := subject.([]any)
+	if !ok {
+		delta.Add(fmt.Sprintf(
+			"%s non-comparable types: %s list and %T.",
+			fp, ld.strategy, subject,
+		))
+		return
+	}
+	switch ld.strategy {
+	case listDirectiveUnordered:
+		if len(ld.items) != len(subjectlist) {
+			delta.Add(fmt.Sprintf(
+				"%s had different lengths. expected %d but found %d",
+				fp, len(ld.items), len(subjectlist),
+			))
+			return
+		}
+		collectUnorderedDifferences(fp, ld.items, subjectlist, meta, delta)
+	case listDirectiveSubset:
+		collectUnorderedDifferences(fp, ld.items, subjectlist, meta, delta)
+	case listDirectiveMerge:
+		key := ld.mergeKey
+		if key == "" {
+			key = mergeKey
+		}
+		if key == "" {
+			delta.Add(fmt.Sprintf(
+				"%s has no known patch merge key. set %s",
+				fp, listDirectiveMergeKey,
+			))
+			return
+		}
+		collectMergedDifferences(fp, key, ld.items, subjectlist, meta, delta)
+	}
+}
+
+// collectUnorderedDifferences pairs each of the supplied match items with a
+// different subject item that it matches, in any order, and adds a
+// difference for each match item that could not be paired.
+func collectUnorderedDifferences(
+	fp string,
+	matchlist []any,
+	subjectlist []any,
+	meta strategicpatch.LookupPatchMeta,
+	d *delta,
+) {
+	// itemDeltas[x][y] contains the differences between match item x and
+	// subject item y.
+	itemDeltas := make([][]*delta, len(matchlist))
+	for x, matchv := range matchlist {
+		itemDeltas[x] = make([]*delta, len(subjectlist))
+		for y, subjectv := range subjectlist {
+			itemDelta := &delta{differences: []string{}}
+			newfp := fmt.Sprintf("%s[%d]", fp, y)
+			collectFieldDifferences(newfp, matchv, subjectv, meta, "", itemDelta)
+			itemDeltas[x][y] = itemDelta
+		}
+	}
+	// We find the most match items that can be paired with different subject
+	// items using augmenting paths, since a greedy pairing could pair a
+	// match item with a subject item that a later match item needs.
+	pairedWith := make([]int, len(subjectlist))
+	for y := range pairedWith {
+		pairedWith[y] = -1
+	}
+	var pair func(x int, seen []bool) bool
+	pair = func(x int, seen []bool) bool {
+		for y := range subjectlist {
+			if seen[y] || !itemDeltas[x][y].Empty() {
+				continue
+			}
+			seen[y] = true
+			if pairedWith[y] < 0 || pair(pairedWith[y], seen) {
+				pairedWith[y] = x
+				return true
+			}
+		}
+		return false
+	}
+	for x := range matchlist {
+		if pair(x, make([]bool, len(subjectlist))) {
+			continue
+		}
+		if len(subjectlist) == 0 {
+			d.Add(fmt.Sprintf(
+				"%s had no item matching item %d. the list is empty", fp, x,
+			))
+			continue
+		}
+		// Report the differences with the closest subject item.
+		closest := 0
+		matching := lo.ContainsBy(itemDeltas[x], func(itemDelta *delta) bool {
+			return itemDelta.Empty()
+		})
+		if matching {
+			d.Add(fmt.Sprintf(
+				"%s had no item matching item %d that did not already "+
+					"match another item", fp, x,
+			))
+			continue
+		}
+		for y := range subjectlist {
+			if len(itemDeltas[x][y].Differences()) <
+				len(itemDeltas[x][closest].Differences()) {
+				closest = y
+			}
+		}
+		d.Add(fmt.Sprintf(
+			"%s had no item matching item %d. closest item %d: %s",
+			fp, x, closest,
+			strings.Join(itemDeltas[x][closest].Differences(), ", "),
+		))
+	}
+}
+
+// collectMergedDifferences pairs each of the supplied match items with the
+// subject item having the same value of the supplied merge key and adds the
+// differences between them.
+func collectMergedDifferences(
+	fp string,
+	key string,
+	matchlist []any,
+	subjectlist []any,
+	meta strategicpatch.LookupPatchMeta,
+	delta *delta,
+) {
+	for x, matchv := range matchlist {
+		matchmap, ok := matchv.(map[string]any)
+		if !ok {
+			delta.Add(fmt.Sprintf(
+				"%s item %d must be an object with a %s field",
+				fp, x, key,
+			))
+			continue
+		}
+		keyv, ok := matchmap[key]
+		if !ok {
+			delta.Add(fmt.Sprintf(
+				"%s item %d has no %s field", fp, x, key,
+			))
+			continue
+		}
+		newfp := fmt.Sprintf("%s[%s=%q]", fp, key, fmt.Sprint(keyv))
+		var subjectv any
+		for _, item := range subjectlist {
+			itemmap, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			if v, ok := itemmap[key]; ok && fmt.Sprint(v) == fmt.Sprint(keyv) {
+				subjectv = item
+				break
+			}
+		}
+		if subjectv == nil {
+			delta.Add(fmt.Sprintf("%s not present in subject", newfp))
+			continue
+		}
+		collectFieldDifferences(newfp, matchv, subjectv, meta, "", delta)
+	}
+}
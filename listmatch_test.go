@@ -0,0 +1,204 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestListDirectives(t *testing.T) {
+	pod := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name": "nginx",
+		},
+		"spec": map[string]any{
+			"containers": []any{
+				map[string]any{"name": "nginx", "image": "nginx:1.25"},
+				map[string]any{"name": "sidecar", "image": "envoy:1.30"},
+			},
+			"tolerations": []any{
+				map[string]any{"key": "a", "operator": "Exists"},
+				map[string]any{"key": "b", "operator": "Exists", "effect": "NoSchedule"},
+			},
+		},
+	}}
+	widget := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]any{
+			"name": "foo",
+		},
+		"spec": map[string]any{
+			"parts": []any{
+				map[string]any{"id": "p1", "size": int64(1)},
+				map[string]any{"id": "p2", "size": int64(2)},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name  string
+		obj   *unstructured.Unstructured
+		match map[string]any
+		diffs []string
+	}{
+		{
+			name: "unordered in a different order",
+			obj:  pod,
+			match: map[string]any{"spec": map[string]any{
+				"containers": map[string]any{"$unordered": []any{
+					map[string]any{"name": "sidecar"},
+					map[string]any{"name": "nginx"},
+				}},
+			}},
+		},
+		{
+			name: "unordered with a different length",
+			obj:  pod,
+			match: map[string]any{"spec": map[string]any{
+				"containers": map[string]any{"$unordered": []any{
+					map[string]any{"name": "nginx"},
+				}},
+			}},
+			diffs: []string{
+				"$.spec.containers had different lengths. expected 1 but found 2",
+			},
+		},
+		{
+			name: "unordered pairs items that a greedy pairing would not",
+			obj:  pod,
+			match: map[string]any{"spec": map[string]any{
+				"tolerations": map[string]any{"$unordered": []any{
+					map[string]any{"operator": "Exists"},
+					map[string]any{"key": "a"},
+				}},
+			}},
+		},
+		{
+			name: "unordered reports the closest item",
+			obj:  pod,
+			match: map[string]any{"spec": map[string]any{
+				"containers": map[string]any{"$unordered": []any{
+					map[string]any{"name": "nginx"},
+					map[string]any{"name": "sidecar", "image": "envoy:1.31"},
+				}},
+			}},
+			diffs: []string{
+				"$.spec.containers had no item matching item 1. closest " +
+					"item 1: $.spec.containers[1].image had different " +
+					"values. expected envoy:1.31 but found envoy:1.30",
+			},
+		},
+		{
+			name: "subset ignores other items",
+			obj:  pod,
+			match: map[string]any{"spec": map[string]any{
+				"containers": map[string]any{"$subset": []any{
+					map[string]any{"image": "envoy:1.30"},
+				}},
+			}},
+		},
+		{
+			name: "subset item matched by only one subject item",
+			obj:  pod,
+			match: map[string]any{"spec": map[string]any{
+				"containers": map[string]any{"$subset": []any{
+					map[string]any{"name": "nginx"},
+					map[string]any{"name": "nginx"},
+				}},
+			}},
+			diffs: []string{
+				"$.spec.containers had no item matching item 1 that did " +
+					"not already match another item",
+			},
+		},
+		{
+			name: "merge by the known patch merge key",
+			obj:  pod,
+			match: map[string]any{"spec": map[string]any{
+				"containers": map[string]any{"$merge": []any{
+					map[string]any{"name": "sidecar", "image": "envoy:1.30"},
+				}},
+			}},
+		},
+		{
+			name: "merge with a different value",
+			obj:  pod,
+			match: map[string]any{"spec": map[string]any{
+				"containers": map[string]any{"$merge": []any{
+					map[string]any{"name": "nginx", "image": "nginx:1.26"},
+				}},
+			}},
+			diffs: []string{
+				`$.spec.containers[name="nginx"].image had different ` +
+					"values. expected nginx:1.26 but found nginx:1.25",
+			},
+		},
+		{
+			name: "merge with a missing item",
+			obj:  pod,
+			match: map[string]any{"spec": map[string]any{
+				"containers": map[string]any{"$merge": []any{
+					map[string]any{"name": "redis"},
+				}},
+			}},
+			diffs: []string{
+				`$.spec.containers[name="redis"] not present in subject`,
+			},
+		},
+		{
+			name: "merge with merge-key on a custom resource",
+			obj:  widget,
+			match: map[string]any{"spec": map[string]any{
+				"parts": map[string]any{
+					"$merge-key": "id",
+					"$merge": []any{
+						map[string]any{"id": "p2", "size": 2},
+					},
+				},
+			}},
+		},
+		{
+			name: "merge without a merge key on a custom resource",
+			obj:  widget,
+			match: map[string]any{"spec": map[string]any{
+				"parts": map[string]any{"$merge": []any{
+					map[string]any{"id": "p2"},
+				}},
+			}},
+			diffs: []string{
+				"$.spec.parts has no known patch merge key. set $merge-key",
+			},
+		},
+		{
+			name: "merge-key with another strategy",
+			obj:  widget,
+			match: map[string]any{"spec": map[string]any{
+				"parts": map[string]any{
+					"$merge-key": "id",
+					"$subset":    []any{map[string]any{"id": "p2"}},
+				},
+			}},
+			diffs: []string{
+				"$.spec.parts $merge-key may only be used with $merge",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta := compareResourceToMatchObject(tt.obj, tt.match)
+			if len(tt.diffs) == 0 {
+				assert.Empty(t, delta.Differences())
+				return
+			}
+			assert.Equal(t, tt.diffs, delta.Differences())
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdt-dev/core/debug"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LogsAction describes the container logs to fetch from one or more Pods,
+// similar to `kubectl logs`.
+type LogsAction struct {
+	// Target is a string or object identifying the Pod(s) to fetch logs from.
+	// It must be one of the following:
+	//
+	// - a string with the name of a Pod, e.g. "nginx"
+	// - a string with a resource kind or kind alias followed by a `/`
+	//   character and the resource name, e.g. "pods/nginx" or
+	//   "deployments/nginx". When the resource is a workload like a
+	//   Deployment, logs are fetched from all Pods selected by the workload's
+	//   `spec.selector`.
+	// - an object with a `type` and optional `name` and `labels` fields.
+	Target *ResourceIdentifier `yaml:"target"`
+	// Container is the name of the container to fetch logs from. If empty,
+	// the Pod's default container is used unless AllContainers is true.
+	Container string `yaml:"container,omitempty"`
+	// AllContainers indicates logs should be fetched from all containers in
+	// the selected Pod(s).
+	AllContainers bool `yaml:"all-containers,omitempty"`
+	// Previous indicates logs should be fetched from the previous terminated
+	// instance of the container.
+	Previous bool `yaml:"previous,omitempty"`
+	// Since is a duration string (e.g. "5m") limiting the logs to those
+	// written more recently than the duration. It must be at least 1s.
+	Since string `yaml:"since,omitempty"`
+	// Tail is the number of most recent log lines to fetch from each
+	// container.
+	Tail *int64 `yaml:"tail,omitempty"`
+}
+
+// Title returns the logs target's kind and name, if present
+func (l *LogsAction) Title() string {
+	if l.Target == nil {
+		return ""
+	}
+	return l.Target.Title()
+}
+
+// podLogOptions returns the PodLogOptions to use for the supplied container
+func (l *LogsAction) podLogOptions(container string) *corev1.PodLogOptions {
+	opts := &corev1.PodLogOptions{
+		Container: container,
+		Previous:  l.Previous,
+		TailLines: l.Tail,
+	}
+	if l.Since != "" {
+		// We validated the duration during parse
+		since, _ := time.ParseDuration(l.Since)
+		secs := int64(since.Seconds())
+		opts.SinceSeconds = &secs
+	}
+	return opts
+}
+
+// NewLogsAction returns a new LogsAction for the supplied container of the
+// target.
+func NewLogsAction(
+	target *ResourceIdentifier,
+	container string,
+) *LogsAction {
+	return &LogsAction{
+		Target:    target,
+		Container: container,
+	}
+}
+
+// logsOutput contains the logs fetched from one or more containers.
+type logsOutput struct {
+	// sources contains the "pod/container" strings that logs were fetched
+	// from
+	sources []string
+	// contents contains the concatenated logs
+	contents string
+}
+
+// asMap returns the logs output as a map[string]any suitable for JSONPath
+// lookups when saving variables.
+func (o *logsOutput) asMap() map[string]any {
+	sources := make([]any, len(o.sources))
+	for x, s := range o.sources {
+		sources[x] = s
+	}
+	return map[string]any{
+		"logs":    strings.TrimSpace(o.contents),
+		"sources": sources,
+	}
+}
+
+// logs fetches the logs from containers in the targeted Pod(s) and populates
+// `out` with a `*logsOutput`.
+func (a *Action) logs(
+	ctx context.Context,
+	c *connection,
+	ns string,
+	out *interface{},
+) error {
+	pods, err := podsForTarget(ctx, c, ns, a.Logs.Target)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no pods found for %s", a.Logs.Target.Title())
+	}
+	res := &logsOutput{}
+	var b strings.Builder
+	for x := range pods {
+		pod := &pods[x]
+		containers := []string{}
+		switch {
+		case a.Logs.AllContainers:
+			for _, ctr := range pod.Spec.Containers {
+				containers = append(containers, ctr.Name)
+			}
+		case a.Logs.Container != "":
+			containers = append(containers, a.Logs.Container)
+		default:
+			containers = append(containers, defaultContainerName(pod))
+		}
+		for _, container := range containers {
+			debug.Printf(
+				ctx, "kube.logs: %s/%s (ns: %s)",
+				pod.Name, container, pod.Namespace,
+			)
+			raw, err := c.clientset.CoreV1().Pods(pod.Namespace).GetLogs(
+				pod.Name, a.Logs.podLogOptions(container),
+			).DoRaw(ctx)
+			if err != nil {
+				return err
+			}
+			// Separate the logs of each container so that the last line of
+			// one container's logs is not joined to the first line of the
+			// next container's logs.
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+				b.WriteString("\n")
+			}
+			b.Write(raw)
+			res.sources = append(res.sources, pod.Name+"/"+container)
+		}
+	}
+	res.contents = b.String()
+	*out = res
+	return nil
+}
+
+// logsOK returns true if the subject is the output of a `kube.logs` action
+// and matches the Logs conditions, false otherwise.
+func (a *assertions) logsOK(ctx context.Context) bool {
+	exp := a.exp
+	out, ok := a.r.(*logsOutput)
+	if !ok || out == nil {
+		return true
+	}
+	return a.pipeOK(ctx, exp.Logs, "logs", out.contents)
+}
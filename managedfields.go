@@ -0,0 +1,177 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+)
+
+// conflictManagerRegex extracts the name of the conflicting field manager
+// from a FieldManagerConflict cause message, which looks like `conflict with
+// "kubectl-edit" using apps/v1`.
+var conflictManagerRegex = regexp.MustCompile(`conflict with "([^"]*)"`)
+
+// conflictCauses returns a map, keyed by field path, of the field managers
+// named in the FieldManagerConflict causes of the supplied status error.
+func conflictCauses(apierr *apierrors.StatusError) map[string]string {
+	res := map[string]string{}
+	details := apierr.ErrStatus.Details
+	if details == nil {
+		return res
+	}
+	for _, cause := range details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		manager := ""
+		if m := conflictManagerRegex.FindStringSubmatch(cause.Message); m != nil {
+			manager = m[1]
+		}
+		res[cause.Field] = manager
+	}
+	return res
+}
+
+// conflictOK returns true if the supplied status error is a field manager
+// conflict that matches the Conflict conditions, false otherwise.
+func (a *assertions) conflictOK(apierr *apierrors.StatusError) bool {
+	exp := a.exp.Conflict
+	if apierr.ErrStatus.Code != http.StatusConflict {
+		a.Fail(ExpectedConflict(fmt.Sprintf(
+			"got status code %d: %s", apierr.ErrStatus.Code, apierr,
+		)))
+		return false
+	}
+	causes := conflictCauses(apierr)
+	if len(causes) == 0 {
+		a.Fail(ExpectedConflict(fmt.Sprintf(
+			"got conflict without field manager causes: %s", apierr,
+		)))
+		return false
+	}
+	ok := true
+	if exp.Manager != "" {
+		managers := map[string]bool{}
+		for _, manager := range causes {
+			managers[manager] = true
+		}
+		if !managers[exp.Manager] {
+			a.Fail(ConflictDoesNotMatch(fmt.Sprintf(
+				"expected conflict with %q but conflict was with %s",
+				exp.Manager, strings.Join(sortedKeys(managers), ", "),
+			)))
+			ok = false
+		}
+	}
+	if exp.Fields != nil {
+		for _, field := range exp.Fields.Values() {
+			manager, found := causes[field]
+			if !found {
+				a.Fail(ConflictDoesNotMatch(fmt.Sprintf(
+					"expected conflict on %s but conflicting fields were %s",
+					field, strings.Join(sortedKeys(causes), ", "),
+				)))
+				ok = false
+				continue
+			}
+			if exp.Manager != "" && manager != exp.Manager {
+				a.Fail(ConflictDoesNotMatch(fmt.Sprintf(
+					"expected conflict on %s with %q but it was with %q",
+					field, exp.Manager, manager,
+				)))
+				ok = false
+			}
+		}
+	}
+	return ok
+}
+
+// managedFieldOwners returns the sorted names of the field managers in the
+// supplied object's `metadata.managedFields` that own the supplied field path
+// or any field beneath it.
+func managedFieldOwners(
+	obj *unstructured.Unstructured,
+	path string,
+) ([]string, error) {
+	owners := map[string]bool{}
+	for _, entry := range obj.GetManagedFields() {
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		set := &fieldpath.Set{}
+		if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			return nil, err
+		}
+		set.Iterate(func(p fieldpath.Path) {
+			ps := p.String()
+			if ps == path || strings.HasPrefix(ps, path+".") ||
+				strings.HasPrefix(ps, path+"[") {
+				owners[entry.Manager] = true
+			}
+		})
+	}
+	return sortedKeys(owners), nil
+}
+
+// managedFieldsOK returns true if the subject's `metadata.managedFields` match
+// the ManagedFields conditions, false otherwise
+func (a *assertions) managedFieldsOK() bool {
+	exp := a.exp
+	if len(exp.ManagedFields) == 0 || !a.hasSubject() {
+		return true
+	}
+	objs, isObjs := a.subjectObjects()
+	if !isObjs {
+		a.Fail(AssertionUnsupported("managed-fields", a.subjectDescription()))
+		return false
+	}
+	ok := true
+	// The managed fields must be owned as expected in each object.
+	for _, obj := range objs {
+		for _, path := range sortedKeys(exp.ManagedFields) {
+			manager := exp.ManagedFields[path]
+			owners, err := managedFieldOwners(obj, path)
+			if err != nil {
+				a.Fail(err)
+				ok = false
+				continue
+			}
+			found := false
+			for _, owner := range owners {
+				if owner == manager {
+					found = true
+				}
+			}
+			if !found {
+				a.Fail(ManagedFieldNotOwned(
+					fmt.Sprintf("%s/%s %s", obj.GetKind(), obj.GetName(), path),
+					manager, owners,
+				))
+				ok = false
+			}
+		}
+	}
+	return ok
+}
+
+// sortedKeys returns the keys of the supplied map, sorted.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
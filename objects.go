@@ -0,0 +1,300 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// objectPtrsAsSlice returns the Object maps of the supplied unstructured
+// objects, e.g. the objects created by `kube.create` or applied by
+// `kube.apply`, suitable for JSONPath lookups.
+func objectPtrsAsSlice(objs []*unstructured.Unstructured) []any {
+	res := make([]any, len(objs))
+	for x, obj := range objs {
+		res[x] = obj.Object
+	}
+	return res
+}
+
+// matchObjectIdentity returns the `kind` and `metadata.name` fields of the
+// supplied match object, if present.
+func matchObjectIdentity(matchObj map[string]any) (string, string) {
+	kind, _, _ := unstructured.NestedString(matchObj, "kind")
+	name, _, _ := unstructured.NestedString(matchObj, "metadata", "name")
+	return kind, name
+}
+
+// selectMatchedObject returns the single object from the supplied objects that
+// the supplied match object identifies by its `kind` and `metadata.name`
+// fields. When there is only one object, the match object need not identify
+// it.
+func selectMatchedObject(
+	objs []*unstructured.Unstructured,
+	matchObj map[string]any,
+) (*unstructured.Unstructured, error) {
+	kind, name := matchObjectIdentity(matchObj)
+	if kind == "" && name == "" {
+		if len(objs) == 1 {
+			return objs[0], nil
+		}
+		return nil, MatchesObjectNotIdentified(fmt.Sprintf(
+			"matches must identify one of %d objects by kind or "+
+				"metadata.name, or be a list matched by index",
+			len(objs),
+		))
+	}
+	var found *unstructured.Unstructured
+	for _, obj := range objs {
+		if kind != "" && obj.GetKind() != kind {
+			continue
+		}
+		if name != "" && obj.GetName() != name {
+			continue
+		}
+		if found != nil {
+			return nil, MatchesObjectNotIdentified(fmt.Sprintf(
+				"more than one object with kind %q and name %q",
+				kind, name,
+			))
+		}
+		found = obj
+	}
+	if found == nil {
+		return nil, MatchesObjectNotIdentified(fmt.Sprintf(
+			"no object with kind %q and name %q", kind, name,
+		))
+	}
+	return found, nil
+}
+
+// objectsMatchesOK returns true if the objects created by `kube.create`,
+// applied by `kube.apply` or waited on by `kube.wait` match the Matches
+// condition, false otherwise.
+//
+// When the Matches condition is a list, each element is matched against the
+// object at the same index, in the order the objects appear in the manifest
+// (or, for `kube.wait`, sorted by name). Otherwise, the Matches condition is
+// matched against the object it identifies by `kind` and `metadata.name`.
+func (a *assertions) objectsMatchesOK(
+	ctx context.Context,
+	objs []*unstructured.Unstructured,
+) bool {
+	type pair struct {
+		obj      *unstructured.Unstructured
+		matchObj map[string]any
+	}
+	pairs := []pair{}
+	switch m := a.exp.Matches.(type) {
+	case []any:
+		if len(m) > len(objs) {
+			a.Fail(MatchesObjectNotIdentified(fmt.Sprintf(
+				"expected at least %d objects but got %d", len(m), len(objs),
+			)))
+			return false
+		}
+		for x, fragment := range m {
+			pairs = append(pairs, pair{objs[x], matchObjectFromAny(ctx, fragment)})
+		}
+	default:
+		matchObj := matchObjectFromAny(ctx, m)
+		obj, err := selectMatchedObject(objs, matchObj)
+		if err != nil {
+			a.Fail(err)
+			return false
+		}
+		pairs = append(pairs, pair{obj, matchObj})
+	}
+	res := true
+	for _, p := range pairs {
+		delta := compareResourceToMatchObject(p.obj, p.matchObj)
+		if !delta.Empty() {
+			for _, diff := range delta.Differences() {
+				a.Fail(MatchesNotEqual(fmt.Sprintf(
+					"%s/%s: %s", p.obj.GetKind(), p.obj.GetName(), diff,
+				)))
+			}
+			expected, actual := matchesDiffViews(p.obj, p.matchObj)
+			a.failDiff(
+				ctx, ErrMatchesNotEqual, "matches", objectTitle(p.obj),
+				expected, actual,
+			)
+			res = false
+		}
+	}
+	return res
+}
+
+// objectTitle returns the kind and name of the supplied object, e.g.
+// "Pod/nginx", for use in failure messages.
+func objectTitle(obj *unstructured.Unstructured) string {
+	return obj.GetKind() + "/" + obj.GetName()
+}
+
+// listMatchesOK returns true if the supplied objects, e.g. the items of a
+// list returned by `kube.get`, match the supplied ListMatches conditions,
+// false otherwise.
+func (a *assertions) listMatchesOK(
+	ctx context.Context,
+	objs []*unstructured.Unstructured,
+	lm *ListMatches,
+) bool {
+	res := true
+	if lm.All != nil {
+		matchObj := matchObjectFromAny(ctx, lm.All)
+		for _, obj := range objs {
+			delta := compareResourceToMatchObject(obj, matchObj)
+			for _, diff := range delta.Differences() {
+				a.Fail(MatchesNotEqual(fmt.Sprintf(
+					"%s: %s", objectTitle(obj), diff,
+				)))
+				res = false
+			}
+			if !delta.Empty() {
+				expected, actual := matchesDiffViews(obj, matchObj)
+				a.failDiff(
+					ctx, ErrMatchesNotEqual, "matches", objectTitle(obj),
+					expected, actual,
+				)
+			}
+		}
+	}
+	if lm.Any != nil {
+		matchObj := matchObjectFromAny(ctx, lm.Any)
+		itemDiffs := []string{}
+		matched := false
+		// The diff is shown for the item with the fewest differences, which
+		// is most likely the item the test author expected to match.
+		var closest *unstructured.Unstructured
+		fewest := 0
+		for _, obj := range objs {
+			delta := compareResourceToMatchObject(obj, matchObj)
+			if delta.Empty() {
+				matched = true
+				break
+			}
+			diffs := delta.Differences()
+			if closest == nil || len(diffs) < fewest {
+				closest, fewest = obj, len(diffs)
+			}
+			itemDiffs = append(itemDiffs, fmt.Sprintf(
+				"%s: %s", objectTitle(obj), strings.Join(diffs, ", "),
+			))
+		}
+		if !matched {
+			a.Fail(MatchesNoItem(len(objs), itemDiffs))
+			res = false
+			if closest != nil {
+				expected, actual := matchesDiffViews(closest, matchObj)
+				a.failDiff(
+					ctx, ErrMatchesNoItem, "matches", objectTitle(closest),
+					expected, actual,
+				)
+			}
+		}
+	}
+	if lm.None != nil {
+		matchObj := matchObjectFromAny(ctx, lm.None)
+		for _, obj := range objs {
+			delta := compareResourceToMatchObject(obj, matchObj)
+			if delta.Empty() {
+				a.Fail(MatchesItemMatched(objectTitle(obj)))
+				res = false
+			}
+		}
+	}
+	if lm.Exactly != nil {
+		res = a.exactlyMatchesOK(ctx, objs, lm.Exactly) && res
+	}
+	return res
+}
+
+// exactlyMatchesOK returns true if the supplied objects are exactly the
+// objects identified by `metadata.name` in the supplied matches fragments, in
+// any order, and each object matches the fragment that identifies it.
+func (a *assertions) exactlyMatchesOK(
+	ctx context.Context,
+	objs []*unstructured.Unstructured,
+	fragments []any,
+) bool {
+	byName := lo.KeyBy(objs, func(obj *unstructured.Unstructured) string {
+		return obj.GetName()
+	})
+	expNames := map[string]bool{}
+	missing := []string{}
+	res := true
+	for _, fragment := range fragments {
+		matchObj := matchObjectFromAny(ctx, fragment)
+		_, name := matchObjectIdentity(matchObj)
+		if name == "" {
+			a.Fail(MatchesObjectNotIdentified(
+				"each matches.exactly item must have a metadata.name",
+			))
+			return false
+		}
+		expNames[name] = true
+		obj, found := byName[name]
+		if !found {
+			missing = append(missing, name)
+			continue
+		}
+		delta := compareResourceToMatchObject(obj, matchObj)
+		for _, diff := range delta.Differences() {
+			a.Fail(MatchesNotEqual(fmt.Sprintf(
+				"%s: %s", objectTitle(obj), diff,
+			)))
+			res = false
+		}
+		if !delta.Empty() {
+			expected, actual := matchesDiffViews(obj, matchObj)
+			a.failDiff(
+				ctx, ErrMatchesNotEqual, "matches", objectTitle(obj),
+				expected, actual,
+			)
+		}
+	}
+	unexpected := []string{}
+	for _, obj := range objs {
+		if !expNames[obj.GetName()] {
+			unexpected = append(unexpected, obj.GetName())
+		}
+	}
+	if len(missing) > 0 || len(unexpected) > 0 {
+		a.Fail(MatchesItemsNotEqual(missing, unexpected))
+		res = false
+	}
+	return res
+}
+
+// subjectObjects returns the Kubernetes objects that the assertions are made
+// about: the object returned by a `kube.get` or `kube.patch`, the items of a
+// returned list, the objects created by `kube.create` or applied by
+// `kube.apply`, the object described by `kube.describe` or the objects waited
+// on by `kube.wait`. The second return value is false when the subject is not
+// one or more Kubernetes objects, e.g. the output of a `kube.exec`.
+func (a *assertions) subjectObjects() ([]*unstructured.Unstructured, bool) {
+	switch r := a.r.(type) {
+	case *unstructured.Unstructured:
+		return []*unstructured.Unstructured{r}, true
+	case *unstructured.UnstructuredList:
+		objs := make([]*unstructured.Unstructured, len(r.Items))
+		for x := range r.Items {
+			objs[x] = &r.Items[x]
+		}
+		return objs, true
+	case []*unstructured.Unstructured:
+		return r, true
+	case *describeOutput:
+		return []*unstructured.Unstructured{r.object}, true
+	case *waitOutput:
+		return r.objects, true
+	}
+	return nil, false
+}
@@ -0,0 +1,308 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// operatorRegex is the `matches` operator requiring the field's value to
+	// match a regular expression.
+	operatorRegex = "$regex"
+	// operatorGT is the `matches` operator requiring the field's value to be
+	// greater than a number or resource quantity.
+	operatorGT = "$gt"
+	// operatorGTE is the `matches` operator requiring the field's value to be
+	// greater than or equal to a number or resource quantity.
+	operatorGTE = "$gte"
+	// operatorLT is the `matches` operator requiring the field's value to be
+	// less than a number or resource quantity.
+	operatorLT = "$lt"
+	// operatorLTE is the `matches` operator requiring the field's value to be
+	// less than or equal to a number or resource quantity.
+	operatorLTE = "$lte"
+	// operatorExists is the `matches` operator requiring the field to be
+	// present (true) or not present (false).
+	operatorExists = "$exists"
+	// operatorAbsent is the `matches` operator requiring the field to not be
+	// present (true) or to be present (false).
+	operatorAbsent = "$absent"
+	// operatorOneOf is the `matches` operator requiring the field's value to
+	// equal one of a list of values.
+	operatorOneOf = "$oneOf"
+)
+
+// operators are the `matches` operators that may be used in place of a
+// field's expected value
+var operators = []string{
+	operatorRegex,
+	operatorGT,
+	operatorGTE,
+	operatorLT,
+	operatorLTE,
+	operatorExists,
+	operatorAbsent,
+	operatorOneOf,
+}
+
+// comparisonOperators are the `matches` operators that compare the field's
+// value with a number or resource quantity
+var comparisonOperators = []string{
+	operatorGT,
+	operatorGTE,
+	operatorLT,
+	operatorLTE,
+}
+
+// operatorsFrom returns the supplied `matches` map and true if the map is an
+// operator expression, e.g. a map with a `$gte` field, instead of the
+// expected content of an object field. An error is returned when the map is
+// a malformed operator expression.
+func operatorsFrom(m map[string]any) (map[string]any, bool, error) {
+	isOperators := false
+	for k := range m {
+		if lo.Contains(operators, k) {
+			isOperators = true
+			break
+		}
+	}
+	if !isOperators {
+		return nil, false, nil
+	}
+	for k, v := range m {
+		switch {
+		case k == operatorRegex:
+			pattern, ok := v.(string)
+			if !ok {
+				return nil, true, fmt.Errorf(
+					"%s must be a string but got %T", k, v,
+				)
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, true, fmt.Errorf("%s: %s", k, err)
+			}
+		case lo.Contains(comparisonOperators, k):
+			if _, ok := quantityOf(v); !ok {
+				return nil, true, fmt.Errorf(
+					"%s must be a number or resource quantity but got %v",
+					k, v,
+				)
+			}
+		case k == operatorExists || k == operatorAbsent:
+			if _, ok := v.(bool); !ok {
+				return nil, true, fmt.Errorf(
+					"%s must be a bool but got %T", k, v,
+				)
+			}
+		case k == operatorOneOf:
+			if _, ok := v.([]any); !ok {
+				return nil, true, fmt.Errorf(
+					"%s must be a list but got %T", k, v,
+				)
+			}
+		default:
+			return nil, true, fmt.Errorf(
+				"unexpected field %q in operator expression", k,
+			)
+		}
+	}
+	if present, ok := m[operatorExists].(bool); ok {
+		if absent, ok := m[operatorAbsent].(bool); ok && present == absent {
+			return nil, true, fmt.Errorf(
+				"%s and %s contradict each other",
+				operatorExists, operatorAbsent,
+			)
+		}
+	}
+	return m, true, nil
+}
+
+// validateOperators returns an error if any operator expression in the
+// supplied `matches` content is malformed.
+func validateOperators(match any) error {
+	switch match := match.(type) {
+	case map[string]any:
+		if _, isOperators, err := operatorsFrom(match); isOperators {
+			return err
+		}
+		for _, v := range match {
+			if err := validateOperators(v); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, v := range match {
+			if err := validateOperators(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expectsAbsent returns true if the supplied `matches` value is an operator
+// expression requiring the field to not be present.
+func expectsAbsent(match any) bool {
+	m, ok := match.(map[string]any)
+	if !ok {
+		return false
+	}
+	ops, isOperators, err := operatorsFrom(m)
+	if !isOperators || err != nil {
+		return false
+	}
+	if absent, ok := ops[operatorAbsent].(bool); ok && absent {
+		return true
+	}
+	if present, ok := ops[operatorExists].(bool); ok && !present {
+		return true
+	}
+	return false
+}
+
+// collectOperatorDifferences evaluates the supplied operator expression
+// against the value of a field that is present in the subject and adds a
+// difference for each operator the value does not satisfy.
+func collectOperatorDifferences(
+	fp string,
+	ops map[string]any,
+	subject any,
+	d *delta,
+) {
+	for _, op := range operators {
+		opv, ok := ops[op]
+		if !ok {
+			continue
+		}
+		switch op {
+		case operatorRegex:
+			sv, ok := scalarString(subject)
+			if !ok || !regexp.MustCompile(opv.(string)).MatchString(sv) {
+				d.Add(fmt.Sprintf(
+					"%s did not match regex %q. found %v", fp, opv, subject,
+				))
+			}
+		case operatorGT, operatorGTE, operatorLT, operatorLTE:
+			mq, _ := quantityOf(opv)
+			sq, ok := quantityOf(subject)
+			if !ok {
+				d.Add(fmt.Sprintf(
+					"%s was not a number or resource quantity. found %v",
+					fp, subject,
+				))
+				continue
+			}
+			cmp := sq.Cmp(mq)
+			satisfied := (op == operatorGT && cmp > 0) ||
+				(op == operatorGTE && cmp >= 0) ||
+				(op == operatorLT && cmp < 0) ||
+				(op == operatorLTE && cmp <= 0)
+			if !satisfied {
+				d.Add(fmt.Sprintf(
+					"%s was not %s %v. found %v",
+					fp, operatorDescriptions[op], opv, subject,
+				))
+			}
+		case operatorExists, operatorAbsent:
+			// The field is present, so only `$exists: false` and
+			// `$absent: true` fail.
+			if opv.(bool) == (op == operatorAbsent) {
+				d.Add(fmt.Sprintf(
+					"%s was present but expected absent. found %v",
+					fp, subject,
+				))
+			}
+		case operatorOneOf:
+			candidates := opv.([]any)
+			found := lo.ContainsBy(candidates, func(candidate any) bool {
+				cd := &delta{differences: []string{}}
+				collectFieldDifferences(fp, candidate, subject, nil, "", cd)
+				return cd.Empty()
+			})
+			if !found {
+				d.Add(fmt.Sprintf(
+					"%s had a value not in %v. found %v",
+					fp, candidates, subject,
+				))
+			}
+		}
+	}
+}
+
+// operatorDescriptions describes the comparison operators in differences
+var operatorDescriptions = map[string]string{
+	operatorGT:  "greater than",
+	operatorGTE: "greater than or equal to",
+	operatorLT:  "less than",
+	operatorLTE: "less than or equal to",
+}
+
+// scalarString returns the string form of the supplied scalar value and true,
+// or false if the value is a map or a list.
+func scalarString(v any) (string, bool) {
+	switch v.(type) {
+	case map[string]any, []any, nil:
+		return "", false
+	}
+	return fmt.Sprint(v), true
+}
+
+// quantityOf returns the supplied number or numeric string as a
+// resource.Quantity and true, or false if the value is not a number or
+// resource quantity, e.g. `500m` or `1Gi`.
+func quantityOf(v any) (resource.Quantity, bool) {
+	switch v := v.(type) {
+	case int, int8, int16, int32, int64:
+		return *resource.NewQuantity(toInt64(v), resource.DecimalSI), true
+	case uint, uint8, uint16, uint32, uint64:
+		return *resource.NewQuantity(int64(toUint64(v)), resource.DecimalSI), true
+	case float32:
+		return quantityOf(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case float64:
+		return quantityOf(strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		q, err := resource.ParseQuantity(strings.TrimSpace(v))
+		if err != nil {
+			return resource.Quantity{}, false
+		}
+		return q, true
+	}
+	return resource.Quantity{}, false
+}
+
+// quantitiesEqual returns true if the supplied values are equal resource
+// quantities, e.g. `500m` and `0.5` or `1Gi` and `1024Mi`. Two plain numeric
+// strings, e.g. `1.10` and `1.1`, are never equal quantities because they are
+// more likely to be versions or identifiers than amounts.
+func quantitiesEqual(match, subject any) bool {
+	ms, mIsString := match.(string)
+	ss, sIsString := subject.(string)
+	if mIsString && sIsString && isPlainNumber(ms) && isPlainNumber(ss) {
+		return false
+	}
+	mq, ok := quantityOf(match)
+	if !ok {
+		return false
+	}
+	sq, ok := quantityOf(subject)
+	if !ok {
+		return false
+	}
+	return mq.Cmp(sq) == 0
+}
+
+// isPlainNumber returns true if the supplied string is a number without a
+// resource quantity suffix.
+func isPlainNumber(s string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil
+}
@@ -0,0 +1,210 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestQuantityOf(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		exp  string
+		ok   bool
+	}{
+		{name: "int", v: 2, exp: "2", ok: true},
+		{name: "int64", v: int64(1500), exp: "1500", ok: true},
+		{name: "uint8", v: uint8(7), exp: "7", ok: true},
+		{name: "float64", v: 0.5, exp: "500m", ok: true},
+		{name: "float32", v: float32(1.5), exp: "1500m", ok: true},
+		{name: "millis", v: "500m", exp: "500m", ok: true},
+		{name: "binary suffix", v: "1Gi", exp: "1Gi", ok: true},
+		{name: "surrounding space", v: " 2 ", exp: "2", ok: true},
+		{name: "not a quantity", v: "nginx", ok: false},
+		{name: "bool", v: true, ok: false},
+		{name: "nil", v: nil, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, ok := quantityOf(tt.v)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.exp, q.String())
+			}
+		})
+	}
+}
+
+func TestQuantitiesEqual(t *testing.T) {
+	tests := []struct {
+		name    string
+		match   any
+		subject any
+		exp     bool
+	}{
+		{name: "millis and float", match: "500m", subject: 0.5, exp: true},
+		{name: "float and millis", match: 0.5, subject: "500m", exp: true},
+		{name: "binary suffixes", match: "1Gi", subject: "1024Mi", exp: true},
+		{name: "int and string", match: 2, subject: "2", exp: true},
+		{name: "int and float", match: int64(2), subject: 2.0, exp: true},
+		{name: "different amounts", match: "1Gi", subject: "1G", exp: false},
+		{name: "plain numeric strings", match: "1.10", subject: "1.1", exp: false},
+		{name: "not quantities", match: "nginx", subject: "nginx", exp: false},
+		{name: "one not a quantity", match: "1", subject: "nginx", exp: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.exp, quantitiesEqual(tt.match, tt.subject))
+		})
+	}
+}
+
+func TestOperators(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]any{
+			"name": "foo-123",
+		},
+		"spec": map[string]any{
+			"replicas": int64(3),
+			"cpu":      "250m",
+			"memory":   "1Gi",
+			"phase":    "Running",
+		},
+	}}
+
+	tests := []struct {
+		name  string
+		match map[string]any
+		diffs []string
+	}{
+		{
+			name: "gt satisfied",
+			match: map[string]any{"spec": map[string]any{
+				"replicas": map[string]any{"$gt": 2},
+			}},
+		},
+		{
+			name: "gt not satisfied when equal",
+			match: map[string]any{"spec": map[string]any{
+				"replicas": map[string]any{"$gt": 3},
+			}},
+			diffs: []string{"$.spec.replicas was not greater than 3. found 3"},
+		},
+		{
+			name: "gte and lte range",
+			match: map[string]any{"spec": map[string]any{
+				"replicas": map[string]any{"$gte": 3, "$lte": 3},
+			}},
+		},
+		{
+			name: "lte with quantities",
+			match: map[string]any{"spec": map[string]any{
+				"cpu": map[string]any{"$lte": "0.25"},
+			}},
+		},
+		{
+			name: "lte not satisfied",
+			match: map[string]any{"spec": map[string]any{
+				"memory": map[string]any{"$lte": "512Mi"},
+			}},
+			diffs: []string{"$.spec.memory was not less than or equal to 512Mi. found 1Gi"},
+		},
+		{
+			name: "lt on a value that is not a quantity",
+			match: map[string]any{"spec": map[string]any{
+				"phase": map[string]any{"$lt": 1},
+			}},
+			diffs: []string{"$.spec.phase was not a number or resource quantity. found Running"},
+		},
+		{
+			name: "regex satisfied",
+			match: map[string]any{"metadata": map[string]any{
+				"name": map[string]any{"$regex": "^foo-[0-9]+$"},
+			}},
+		},
+		{
+			name: "regex not satisfied",
+			match: map[string]any{"metadata": map[string]any{
+				"name": map[string]any{"$regex": "^bar-"},
+			}},
+			diffs: []string{`$.metadata.name did not match regex "^bar-". found foo-123`},
+		},
+		{
+			name: "oneOf satisfied",
+			match: map[string]any{"spec": map[string]any{
+				"phase": map[string]any{"$oneOf": []any{"Pending", "Running"}},
+			}},
+		},
+		{
+			name: "oneOf with an equal quantity",
+			match: map[string]any{"spec": map[string]any{
+				"cpu": map[string]any{"$oneOf": []any{0.25, "500m"}},
+			}},
+		},
+		{
+			name: "oneOf not satisfied",
+			match: map[string]any{"spec": map[string]any{
+				"phase": map[string]any{"$oneOf": []any{"Failed", "Succeeded"}},
+			}},
+			diffs: []string{"$.spec.phase had a value not in [Failed Succeeded]. found Running"},
+		},
+		{
+			name: "absent field not present",
+			match: map[string]any{"spec": map[string]any{
+				"paused": map[string]any{"$absent": true},
+			}},
+		},
+		{
+			name: "absent field present",
+			match: map[string]any{"spec": map[string]any{
+				"phase": map[string]any{"$absent": true},
+			}},
+			diffs: []string{"$.spec.phase was present but expected absent. found Running"},
+		},
+		{
+			name: "absent false requires the field",
+			match: map[string]any{"spec": map[string]any{
+				"paused": map[string]any{"$absent": false},
+			}},
+			diffs: []string{"$.spec.paused not present in subject"},
+		},
+		{
+			name: "exists false on a missing field",
+			match: map[string]any{"spec": map[string]any{
+				"paused": map[string]any{"$exists": false},
+			}},
+		},
+		{
+			name: "contradicting exists and absent",
+			match: map[string]any{"spec": map[string]any{
+				"phase": map[string]any{"$exists": true, "$absent": true},
+			}},
+			diffs: []string{"$.spec.phase $exists and $absent contradict each other"},
+		},
+		{
+			name: "unknown field in an operator expression",
+			match: map[string]any{"spec": map[string]any{
+				"replicas": map[string]any{"$gt": 1, "max": 5},
+			}},
+			diffs: []string{`$.spec.replicas unexpected field "max" in operator expression`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta := compareResourceToMatchObject(obj, tt.match)
+			if len(tt.diffs) == 0 {
+				assert.Empty(t, delta.Differences())
+				return
+			}
+			assert.Equal(t, tt.diffs, delta.Differences())
+		})
+	}
+}
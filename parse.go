@@ -7,12 +7,15 @@ package kube
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gdt-dev/core/api"
 	gdtjson "github.com/gdt-dev/core/assertion/json"
 	"github.com/gdt-dev/core/parse"
+	"github.com/google/shlex"
 	"github.com/samber/lo"
 	"github.com/theory/jsonpath"
 	"gopkg.in/yaml.v3"
@@ -92,6 +95,97 @@ func InvalidMatchesAt(node *yaml.Node) error {
 	}
 }
 
+// InvalidListDirectiveAt returns a parse error indicating a
+// `kube.assert.matches` list directive, e.g. `$subset`, is malformed.
+func InvalidListDirectiveAt(err error, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"invalid `kube.assert.matches` list directive: %s", err,
+		),
+	}
+}
+
+// InvalidOperatorAt returns a parse error indicating a `kube.assert.matches`
+// operator expression, e.g. `$regex`, is malformed.
+func InvalidOperatorAt(err error, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"invalid `kube.assert.matches` operator: %s", err,
+		),
+	}
+}
+
+// InvalidReadyStatusAt returns a parse error indicating that
+// `kube.assert.ready` is neither a bool nor a valid computed status.
+func InvalidReadyStatusAt(node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"expected `kube.assert.ready` to be true, false or one of %s "+
+				"but got %q",
+			strings.Join(readyStatuses, ", "), node.Value,
+		),
+	}
+}
+
+// InvalidLenRangeAt returns a parse error indicating that the
+// `kube.assert.len` range is malformed.
+func InvalidLenRangeAt(msg string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("invalid `kube.assert.len` range: %s", msg),
+	}
+}
+
+// InvalidAggregateAt returns a parse error indicating that a
+// `kube.assert.aggregate` item is malformed.
+func InvalidAggregateAt(msg string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("invalid `kube.assert.aggregate`: %s", msg),
+	}
+}
+
+// InvalidSnapshotAt returns a parse error indicating that the
+// `kube.assert.snapshot` is malformed.
+func InvalidSnapshotAt(msg string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("invalid `kube.assert.snapshot`: %s", msg),
+	}
+}
+
+// InvalidCELAt returns a parse error indicating a `kube.assert.cel`
+// expression failed to compile or type-check.
+func InvalidCELAt(expr string, err error, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"invalid `kube.assert.cel` expression %q: %s", expr, err,
+		),
+	}
+}
+
+// MatchesNameRequiredAt returns a parse error indicating a
+// `kube.assert.matches.exactly` item does not identify an object by its
+// `metadata.name`.
+func MatchesNameRequiredAt(node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: "`kube.assert.matches.exactly` items must have a `metadata.name`",
+	}
+}
+
 // InvalidMatchesUnmarshalErrorAt returns a parse error indicating the
 // `Kube.Assert.Matches` value is malformed and we could not unmarshal it.
 func InvalidMatchesUnmarshalErrorAt(err error, node *yaml.Node) error {
@@ -117,6 +211,292 @@ func InvalidConditionMatchAt(err error, node *yaml.Node) error {
 	}
 }
 
+// InvalidPatchTypeAt returns a parse error indicating the `kube.patch.type`
+// value is not one of the valid patch types.
+func InvalidPatchTypeAt(subject string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"invalid patch type %q. expected one of %s",
+			subject, strings.Join(validPatchTypes, ", "),
+		),
+	}
+}
+
+// InvalidPatchAt returns a parse error indicating the `kube.patch.patch`
+// value is malformed.
+func InvalidPatchAt(msg string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("`kube.patch.patch` not well-formed: %s", msg),
+	}
+}
+
+// ExecFieldRequiredAt returns a parse error indicating the test author did
+// not include a required field in the `kube.exec` object.
+func ExecFieldRequiredAt(field string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("`kube.exec.%s` is required", field),
+	}
+}
+
+// InvalidExecCommandAt returns a parse error indicating the
+// `kube.exec.command` value could not be split into arguments.
+func InvalidExecCommandAt(err error, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"`kube.exec.command` not well-formed: %s", err,
+		),
+	}
+}
+
+// LogsFieldRequiredAt returns a parse error indicating the test author did
+// not include a required field in the `kube.logs` object.
+func LogsFieldRequiredAt(field string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("`kube.logs.%s` is required", field),
+	}
+}
+
+// LogsContainerConflictAt returns a parse error indicating the test author
+// specified both `container` and `all-containers` in the `kube.logs` object.
+func LogsContainerConflictAt(node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: "specify either `kube.logs.container` or " +
+			"`kube.logs.all-containers`, not both",
+	}
+}
+
+// WatchFieldRequiredAt returns a parse error indicating the test author did
+// not include a required field in the `kube.watch` object.
+func WatchFieldRequiredAt(field string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("`kube.watch.%s` is required", field),
+	}
+}
+
+// WatchDurationOrUntilRequiredAt returns a parse error indicating the test
+// author did not specify when a `kube.watch` should stop.
+func WatchDurationOrUntilRequiredAt(node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: "either `kube.watch.duration` or `kube.watch.until` " +
+			"is required",
+	}
+}
+
+// WatchDurationExceedsTimeoutAt returns a parse error indicating the test
+// author specified a `kube.watch.duration` that does not fit in the test
+// spec's timeout, less the margin the watch keeps for reporting.
+func WatchDurationExceedsTimeoutAt(
+	duration string,
+	timeout string,
+	node *yaml.Node,
+) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"kube.watch duration %s must be less than the test spec's "+
+				"timeout of %s, less a margin of a tenth of the timeout "+
+				"(up to %s) for reporting",
+			duration, timeout, waitReportMargin,
+		),
+	}
+}
+
+// InvalidStatusCodeAt returns a parse error indicating the test author
+// specified an `assert.status.code` that is not an HTTP error status code.
+func InvalidStatusCodeAt(code int, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"invalid status code %d. expected an HTTP error status code "+
+				"between 400 and 599",
+			code,
+		),
+	}
+}
+
+// InvalidPlacementCountAt returns a parse error indicating the test author
+// specified an `assert.placement` `max-skew` or `min-domains` that is not a
+// positive integer.
+func InvalidPlacementCountAt(field string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"invalid %s %s. expected a positive integer",
+			field, node.Value,
+		),
+	}
+}
+
+// InvalidTaintAt returns a parse error indicating the test author specified
+// an `assert.placement.avoid-taints` taint that is not in the
+// `key[=value][:effect]` format.
+func InvalidTaintAt(err error, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"invalid taint: %s. expected key[=value][:effect]", err,
+		),
+	}
+}
+
+// StatusCauseFieldRequiredAt returns a parse error indicating the test author
+// specified an `assert.status.causes` entry without any of the `field`,
+// `type` or `message` fields.
+func StatusCauseFieldRequiredAt(node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: "each entry in `assert.status.causes` must have at least " +
+			"one of `field`, `type` or `message`",
+	}
+}
+
+// InvalidFieldPathAt returns a parse error indicating the test author
+// specified a field path in `assert.conflict` or `assert.managed-fields` that
+// does not start with a `.`.
+func InvalidFieldPathAt(path string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"invalid field path %q. field paths must start with `.`, "+
+				"e.g. `.spec.replicas`",
+			path,
+		),
+	}
+}
+
+// EventsFieldRequiredAt returns a parse error indicating the test author did
+// not include a required field in the `kube.events` object.
+func EventsFieldRequiredAt(field string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("`kube.events.%s` is required", field),
+	}
+}
+
+// PlacementFieldRequiredAt returns a parse error indicating the test author
+// did not include a required field in the `assert.placement` object.
+func PlacementFieldRequiredAt(field string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("`assert.placement.%s` is required", field),
+	}
+}
+
+// InvalidEventTypeAt returns a parse error indicating the test author
+// specified an `assert.events.type` that is not a valid Event type.
+func InvalidEventTypeAt(typ string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"invalid event type %q: expected %q or %q",
+			typ, EventTypeNormal, EventTypeWarning,
+		),
+	}
+}
+
+// InvalidDryRunAt returns a parse error indicating the test author specified
+// an unknown `dry-run` mode.
+func InvalidDryRunAt(mode string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"invalid dry-run %q: expected %q or %q",
+			mode, DryRunServer, DryRunNone,
+		),
+	}
+}
+
+// WaitFieldRequiredAt returns a parse error indicating the test author did
+// not include a required field in the `kube.wait` object.
+func WaitFieldRequiredAt(field string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("`kube.wait.%s` is required", field),
+	}
+}
+
+// InvalidWaitForAt returns a parse error indicating the `kube.wait.for`
+// field could not be parsed.
+func InvalidWaitForAt(subject string, err error, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"invalid `kube.wait.for` value %q: %s", subject, err,
+		),
+	}
+}
+
+// DescribeNameRequiredAt returns a parse error indicating the test author
+// did not identify a single named resource in the `kube.describe` field.
+func DescribeNameRequiredAt(node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: "`kube.describe` requires a resource name, e.g. " +
+			"`deployments/nginx`",
+	}
+}
+
+// InvalidDurationAt returns a parse error indicating a duration string could
+// not be parsed.
+func InvalidDurationAt(err error, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("invalid duration: %s", err),
+	}
+}
+
+// InvalidRegexAt returns a parse error indicating a regular expression could
+// not be compiled.
+func InvalidRegexAt(expr string, err error, node *yaml.Node) error {
+	return &parse.Error{
+		Line:   node.Line,
+		Column: node.Column,
+		Message: fmt.Sprintf(
+			"invalid regular expression %q: %s", expr, err,
+		),
+	}
+}
+
+// PatchFieldRequiredAt returns a parse error indicating the test author did
+// not include a required field in the `kube.patch` object.
+func PatchFieldRequiredAt(field string, node *yaml.Node) error {
+	return &parse.Error{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf("`kube.patch.%s` is required", field),
+	}
+}
+
 // InvalidWithLabelsAt returns a parse error indicating the test author included
 // `kube.with.labels` but did not specify either `kube.get` or `kube.delete`.
 func InvalidWithLabelsAt(err error, node *yaml.Node) error {
@@ -147,6 +527,8 @@ func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
 	// We do an initial pass over the shortcut fields, then all the
 	// non-shortcut fields after that.
 	var ks *KubeSpec
+	// timeoutNode is the test spec's `timeout` field, if any
+	var timeoutNode *yaml.Node
 
 	// maps/structs are stored in a top-level Node.Content field which is a
 	// concatenated slice of Node pointers in pairs of key/values.
@@ -213,44 +595,142 @@ func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
 			ks = &KubeSpec{}
 			ks.Delete = v
 			s.Kube = ks
-		}
-	}
-
-	for i := 0; i < len(node.Content); i += 2 {
-		keyNode := node.Content[i]
-		if keyNode.Kind != yaml.ScalarNode {
-			return parse.ExpectedScalarAt(keyNode)
-		}
-		key := keyNode.Value
-		valNode := node.Content[i+1]
-		switch key {
-		case "kube":
+		case "kube.patch":
 			if valNode.Kind != yaml.MappingNode {
 				return parse.ExpectedMapAt(valNode)
 			}
 			if ks != nil {
-				return EitherShortcutOrKubeSpecAt(valNode)
+				return MoreThanOneKubeActionAt(valNode)
 			}
-			if err := valNode.Decode(&ks); err != nil {
+			var v *PatchAction
+			if err := valNode.Decode(&v); err != nil {
 				return err
 			}
+			ks = &KubeSpec{}
+			ks.Patch = v
 			s.Kube = ks
-		case "var":
+		case "kube.exec":
 			if valNode.Kind != yaml.MappingNode {
 				return parse.ExpectedMapAt(valNode)
 			}
-			var specVars Variables
-			if err := valNode.Decode(&specVars); err != nil {
+			if ks != nil {
+				return MoreThanOneKubeActionAt(valNode)
+			}
+			var v *ExecAction
+			if err := valNode.Decode(&v); err != nil {
 				return err
 			}
-			vars = lo.Assign(specVars, vars)
-		case "assert":
+			ks = &KubeSpec{}
+			ks.Exec = v
+			s.Kube = ks
+		case "kube.logs":
 			if valNode.Kind != yaml.MappingNode {
 				return parse.ExpectedMapAt(valNode)
 			}
-			var e *Expect
-			if err := valNode.Decode(&e); err != nil {
-				return err
+			if ks != nil {
+				return MoreThanOneKubeActionAt(valNode)
+			}
+			var v *LogsAction
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			ks = &KubeSpec{}
+			ks.Logs = v
+			s.Kube = ks
+		case "kube.describe":
+			if valNode.Kind != yaml.ScalarNode && valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedScalarOrMapAt(valNode)
+			}
+			if ks != nil {
+				return MoreThanOneKubeActionAt(valNode)
+			}
+			v, err := describeTargetFromNode(valNode)
+			if err != nil {
+				return err
+			}
+			ks = &KubeSpec{}
+			ks.Describe = v
+			s.Kube = ks
+		case "kube.wait":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			if ks != nil {
+				return MoreThanOneKubeActionAt(valNode)
+			}
+			var v *WaitAction
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			ks = &KubeSpec{}
+			ks.Wait = v
+			s.Kube = ks
+		case "kube.watch":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			if ks != nil {
+				return MoreThanOneKubeActionAt(valNode)
+			}
+			var v *WatchAction
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			ks = &KubeSpec{}
+			ks.Watch = v
+			s.Kube = ks
+		case "kube.events":
+			if valNode.Kind != yaml.ScalarNode && valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedScalarOrMapAt(valNode)
+			}
+			if ks != nil {
+				return MoreThanOneKubeActionAt(valNode)
+			}
+			var v *EventsAction
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			ks = &KubeSpec{}
+			ks.Events = v
+			s.Kube = ks
+		}
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "kube":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			if ks != nil {
+				return EitherShortcutOrKubeSpecAt(valNode)
+			}
+			if err := valNode.Decode(&ks); err != nil {
+				return err
+			}
+			s.Kube = ks
+		case "var":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var specVars Variables
+			if err := valNode.Decode(&specVars); err != nil {
+				return err
+			}
+			vars = lo.Assign(specVars, vars)
+		case "assert":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var e *Expect
+			if err := valNode.Decode(&e); err != nil {
+				return err
 			}
 			s.Assert = e
 		case "require":
@@ -263,8 +743,14 @@ func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
 			}
 			e.Require = true
 			s.Assert = e
-		case "kube.get", "kube.create", "kube.delete", "kube.apply":
+		case "kube.get", "kube.create", "kube.delete", "kube.apply",
+			"kube.patch", "kube.exec", "kube.logs", "kube.describe",
+			"kube.wait", "kube.watch", "kube.events":
 			continue
+		case "timeout":
+			// The base spec parses and validates the timeout. We only need it
+			// to check the `kube.watch` duration against it below.
+			timeoutNode = valNode
 		default:
 			if lo.Contains(api.BaseSpecFields, key) {
 				continue
@@ -275,6 +761,38 @@ func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
 	if len(vars) > 0 {
 		s.Var = vars
 	}
+	if timeoutNode != nil && ks != nil && ks.Watch != nil {
+		return validateWatchDuration(ks.Watch, timeoutNode)
+	}
+	return nil
+}
+
+// validateWatchDuration returns a parse error if the supplied WatchAction's
+// `duration` does not fit in the test spec's timeout, less the margin the
+// watch keeps for reporting, since the watch would be stopped by the timeout
+// before the duration elapsed.
+func validateWatchDuration(w *WatchAction, timeoutNode *yaml.Node) error {
+	if w.Duration == "" {
+		return nil
+	}
+	after := timeoutNode.Value
+	if timeoutNode.Kind == yaml.MappingNode {
+		var to api.Timeout
+		if err := timeoutNode.Decode(&to); err != nil {
+			// The base spec reports the invalid timeout.
+			return nil
+		}
+		after = to.After
+	}
+	timeout, err := time.ParseDuration(after)
+	if err != nil {
+		return nil
+	}
+	// We validated the duration during parse
+	d, _ := time.ParseDuration(w.Duration)
+	if d > timeout-reportMargin(timeout) {
+		return WatchDurationExceedsTimeoutAt(w.Duration, after, timeoutNode)
+	}
 	return nil
 }
 
@@ -314,7 +832,39 @@ func (s *KubeSpec) UnmarshalYAML(node *yaml.Node) error {
 				return parse.ExpectedScalarAt(valNode)
 			}
 			s.Namespace = valNode.Value
-		case "get", "create", "apply", "delete":
+		case "dry-run":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			if !validDryRun(valNode.Value) {
+				return InvalidDryRunAt(valNode.Value, valNode)
+			}
+			s.DryRun = valNode.Value
+		case "field-manager":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			s.FieldManager = valNode.Value
+		case "force":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.ParseBool(valNode.Value)
+			if err != nil {
+				return parse.ExpectedBoolAt(valNode)
+			}
+			s.Force = &v
+		case "fail-on-deprecation":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.ParseBool(valNode.Value)
+			if err != nil {
+				return parse.ExpectedBoolAt(valNode)
+			}
+			s.FailOnDeprecation = &v
+		case "get", "create", "apply", "delete", "patch", "exec", "logs",
+			"describe", "wait", "watch", "events":
 			// Because Action is an embedded struct and we parse it below, just
 			// ignore these fields in the top-level `kube:` field for now.
 		default:
@@ -383,6 +933,69 @@ func (a *Action) UnmarshalYAML(node *yaml.Node) error {
 				return err
 			}
 			a.Delete = v
+		case "patch":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v *PatchAction
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			a.Patch = v
+		case "exec":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v *ExecAction
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			a.Exec = v
+		case "logs":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v *LogsAction
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			a.Logs = v
+		case "describe":
+			if valNode.Kind != yaml.ScalarNode && valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedScalarOrMapAt(valNode)
+			}
+			v, err := describeTargetFromNode(valNode)
+			if err != nil {
+				return err
+			}
+			a.Describe = v
+		case "wait":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v *WaitAction
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			a.Wait = v
+		case "watch":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v *WatchAction
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			a.Watch = v
+		case "events":
+			if valNode.Kind != yaml.ScalarNode && valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedScalarOrMapAt(valNode)
+			}
+			var v *EventsAction
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			a.Events = v
 		}
 	}
 	if moreThanOneAction(a) {
@@ -425,8 +1038,16 @@ func (e *Expect) UnmarshalYAML(node *yaml.Node) error {
 			}
 			e.Error = v
 		case "len":
+			if valNode.Kind == yaml.MappingNode {
+				var v *LenRange
+				if err := valNode.Decode(&v); err != nil {
+					return err
+				}
+				e.LenRange = v
+				continue
+			}
 			if valNode.Kind != yaml.ScalarNode {
-				return parse.ExpectedScalarAt(valNode)
+				return parse.ExpectedScalarOrMapAt(valNode)
 			}
 			var v *int
 			if err := valNode.Decode(&v); err != nil {
@@ -464,41 +1085,58 @@ func (e *Expect) UnmarshalYAML(node *yaml.Node) error {
 			if valNode.Kind != yaml.MappingNode {
 				return parse.ExpectedMapAt(valNode)
 			}
+			if isConditionsAnyAllNode(valNode) {
+				for j := 0; j < len(valNode.Content); j += 2 {
+					modeNode := valNode.Content[j]
+					condsNode := valNode.Content[j+1]
+					if condsNode.Kind != yaml.MappingNode {
+						return parse.ExpectedMapAt(condsNode)
+					}
+					var v map[string]*ConditionMatch
+					if err := condsNode.Decode(&v); err != nil {
+						return err
+					}
+					if modeNode.Value == "any" {
+						e.ConditionsAny = v
+					} else {
+						e.Conditions = v
+					}
+				}
+				continue
+			}
 			var v map[string]*ConditionMatch
 			if err := valNode.Decode(&v); err != nil {
 				return err
 			}
 			e.Conditions = v
 		case "matches":
-			if valNode.Kind == yaml.MappingNode {
-				var v map[string]interface{}
-				if err := valNode.Decode(&v); err != nil {
-					return err
-				}
-				e.Matches = v
-			} else if valNode.Kind == yaml.ScalarNode {
-				if valNode.Tag == "!!null" {
-					return parse.ExpectedMapOrYAMLStringAt(valNode)
+			if valNode.Kind == yaml.SequenceNode {
+				// A list of matches is matched by index against the objects
+				// created by `kube.create` or applied by `kube.apply`.
+				fragments := make([]any, len(valNode.Content))
+				for x, fragNode := range valNode.Content {
+					v, err := matchesFromNode(fragNode)
+					if err != nil {
+						return err
+					}
+					fragments[x] = v
 				}
-				var v string
+				e.Matches = fragments
+				continue
+			}
+			if isListMatchesNode(valNode) {
+				var v *ListMatches
 				if err := valNode.Decode(&v); err != nil {
 					return err
 				}
-				if probablyFilePath(v) {
-					if !fileExists(v) {
-						return parse.FileNotFoundAt(v, valNode)
-					}
-				}
-				// inline YAML. check it can be unmarshaled into a
-				// map[string]interface{}
-				var m map[string]interface{}
-				if err := yaml.Unmarshal([]byte(v), &m); err != nil {
-					return InvalidMatchesUnmarshalErrorAt(err, valNode)
-				}
-				e.Matches = m
-			} else {
-				return parse.ExpectedMapOrYAMLStringAt(valNode)
+				e.Matches = v
+				continue
 			}
+			v, err := matchesFromNode(valNode)
+			if err != nil {
+				return err
+			}
+			e.Matches = v
 		case "placement":
 			if valNode.Kind != yaml.MappingNode {
 				return parse.ExpectedMapAt(valNode)
@@ -508,111 +1146,1333 @@ func (e *Expect) UnmarshalYAML(node *yaml.Node) error {
 				return err
 			}
 			e.Placement = v
-		default:
-			return parse.UnknownFieldAt(key, keyNode)
-		}
-	}
-	return nil
-}
-
-// UnmarshalYAML is a custom unmarshaler that understands that the value of the
-// ConditionMatch can be either a string, a slice of strings, or an object with .
-func (m *ConditionMatch) UnmarshalYAML(node *yaml.Node) error {
-	if node.Kind == yaml.ScalarNode || node.Kind == yaml.SequenceNode {
-		var fs api.FlexStrings
-		if err := node.Decode(&fs); err != nil {
-			return InvalidConditionMatchAt(err, node)
-		}
-		m.conditionMatch = conditionMatch{Status: &fs}
-	}
-	if node.Kind == yaml.MappingNode {
-		var cm conditionMatch
-		if err := node.Decode(&cm); err != nil {
-			return InvalidConditionMatchAt(err, node)
-		}
-		m.conditionMatch = cm
-		return nil
-	}
-	return nil
-}
-
-// UnmarshalYAML is a custom unmarshaler that understands that the value of the
-// ResourceIdentifier can be either a string or a selector.
-func (r *ResourceIdentifier) UnmarshalYAML(node *yaml.Node) error {
-	if node.Kind != yaml.ScalarNode && node.Kind != yaml.MappingNode {
-		return parse.ExpectedScalarOrMapAt(node)
-	}
-	var s string
-	// A resource identifier can be a string of the form {type}/{name} or
-	// {type}.
-	if err := node.Decode(&s); err == nil {
-		if strings.ContainsAny(s, " ,;\n\t\r") {
-			return InvalidResourceSpecifierAt(s, node)
-		}
-		if strings.Count(s, "/") > 1 {
-			return InvalidResourceSpecifierAt(s, node)
-		}
-		r.Arg, r.Name = splitArgName(s)
-		return nil
-	}
-	// Otherwise the resource identifier should be specified broken out as a
-	// struct with a `type` and `labels` field.
-	var ri resourceIdentifierWithSelector
-	if err := node.Decode(&ri); err != nil {
-		return err
-	}
-	_, err := labels.ValidatedSelectorFromSet(ri.Labels)
-	if err != nil {
-		return InvalidWithLabelsAt(err, node)
-	}
+		case "exit-code":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			ec, err := strconv.Atoi(valNode.Value)
+			if err != nil {
+				return parse.ExpectedIntAt(valNode)
+			}
+			e.ExitCode = ec
+		case "out", "err", "logs":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v *PipeExpect
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			switch key {
+			case "out":
+				e.Out = v
+			case "err":
+				e.Err = v
+			case "logs":
+				e.Logs = v
+			}
+		case "watch":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v *WatchExpect
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			e.Watch = v
+		case "events":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v *EventsExpect
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			e.Events = v
+		case "status":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v *StatusExpect
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			e.Status = v
+		case "conflict":
+			if valNode.Kind == yaml.ScalarNode {
+				// `conflict: true` asserts there was a conflict with any
+				// field manager on any fields.
+				v, err := strconv.ParseBool(valNode.Value)
+				if err != nil {
+					return parse.ExpectedBoolAt(valNode)
+				}
+				if v {
+					e.Conflict = &ConflictExpect{}
+				}
+				continue
+			}
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedScalarOrMapAt(valNode)
+			}
+			var v *ConflictExpect
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			e.Conflict = v
+		case "managed-fields":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v map[string]string
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			for path := range v {
+				if !strings.HasPrefix(path, ".") {
+					return InvalidFieldPathAt(path, valNode)
+				}
+			}
+			e.ManagedFields = v
+		case "warnings":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v *WarningsExpect
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			e.Warnings = v
+		case "aggregate":
+			if valNode.Kind != yaml.SequenceNode {
+				return parse.ExpectedSequenceAt(valNode)
+			}
+			var v []*AggregateExpect
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			e.Aggregates = v
+		case "ready":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			if v, err := strconv.ParseBool(valNode.Value); err == nil {
+				e.Ready = &ReadyExpect{Status: ReadyStatusCurrent, Not: !v}
+				continue
+			}
+			status, ok := isReadyStatus(valNode.Value)
+			if !ok {
+				return InvalidReadyStatusAt(valNode)
+			}
+			e.Ready = &ReadyExpect{Status: status}
+		case "cel":
+			var v *api.FlexStrings
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			env, err := celEnv()
+			if err != nil {
+				return err
+			}
+			for _, expr := range v.Values() {
+				if _, err := compileCEL(env, expr); err != nil {
+					return InvalidCELAt(expr, err, valNode)
+				}
+			}
+			e.CEL = v
+		case "snapshot":
+			var v *SnapshotExpect
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			e.Snapshot = v
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that validates the
+// PlacementAssertion's `max-skew` and `min-domains` fields.
+func (p *PlacementAssertion) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "spread":
+			var v *api.FlexStrings
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			p.Spread = v
+		case "pack":
+			var v *api.FlexStrings
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			p.Pack = v
+		case "max-skew", "min-domains":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.Atoi(valNode.Value)
+			if err != nil {
+				return parse.ExpectedIntAt(valNode)
+			}
+			if v < 1 {
+				return InvalidPlacementCountAt(key, valNode)
+			}
+			if key == "max-skew" {
+				p.MaxSkew = &v
+			} else {
+				p.MinDomains = v
+			}
+		case "node-selector":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v map[string]string
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			p.NodeSelector = v
+		case "avoid-taints":
+			var v *api.FlexStrings
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			for _, spec := range v.Values() {
+				if _, err := parseTaint(spec); err != nil {
+					return InvalidTaintAt(err, valNode)
+				}
+			}
+			p.AvoidTaints = v
+		case "affinity", "anti-affinity":
+			if valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedMapAt(valNode)
+			}
+			var v *PlacementAffinity
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			if v.Target == nil {
+				return PlacementFieldRequiredAt(key+".target", valNode)
+			}
+			if key == "affinity" {
+				p.Affinity = v
+			} else {
+				p.AntiAffinity = v
+			}
+		case "max-pods-per-node":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.Atoi(valNode.Value)
+			if err != nil {
+				return parse.ExpectedIntAt(valNode)
+			}
+			if v < 1 {
+				return InvalidPlacementCountAt(key, valNode)
+			}
+			p.MaxPodsPerNode = &v
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that validates the WarningsExpect's
+// `none` and `count` fields.
+func (e *WarningsExpect) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "contains":
+			var v *api.FlexStrings
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			e.Contains = v
+		case "none":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.ParseBool(valNode.Value)
+			if err != nil {
+				return parse.ExpectedBoolAt(valNode)
+			}
+			e.None = v
+		case "count":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.Atoi(valNode.Value)
+			if err != nil {
+				return parse.ExpectedIntAt(valNode)
+			}
+			e.Count = &v
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that validates the LenRange's
+// minimum and maximum.
+func (r *LenRange) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "min", "max":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.Atoi(valNode.Value)
+			if err != nil || v < 0 {
+				return InvalidLenRangeAt(
+					fmt.Sprintf("%s must be a non-negative integer", key),
+					valNode,
+				)
+			}
+			if key == "min" {
+				r.Min = &v
+			} else {
+				r.Max = &v
+			}
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if r.Min == nil && r.Max == nil {
+		return InvalidLenRangeAt("one of min or max is required", node)
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return InvalidLenRangeAt("min must not be greater than max", node)
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that validates the AggregateExpect's
+// JSONPath expression and expected values.
+func (e *AggregateExpect) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		if valNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(valNode)
+		}
+		switch key {
+		case "count", "sum":
+			path := valNode.Value
+			if len(path) == 0 || path[0] != '$' {
+				return gdtjson.JSONPathInvalidNoRoot(path, valNode)
+			}
+			if _, err := jsonpath.Parse(path); err != nil {
+				return gdtjson.JSONPathInvalid(path, err, valNode)
+			}
+			if key == "count" {
+				e.Count = path
+			} else {
+				e.Sum = path
+			}
+		case "equals", "min", "max":
+			if _, ok := quantityOf(valNode.Value); !ok {
+				return InvalidAggregateAt(fmt.Sprintf(
+					"%s must be a number or resource quantity but got %q",
+					key, valNode.Value,
+				), valNode)
+			}
+			switch key {
+			case "equals":
+				e.Equals = valNode.Value
+			case "min":
+				e.Min = valNode.Value
+			case "max":
+				e.Max = valNode.Value
+			}
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if (e.Count == "") == (e.Sum == "") {
+		return InvalidAggregateAt("exactly one of count or sum is required", node)
+	}
+	if e.Equals == "" && e.Min == "" && e.Max == "" {
+		return InvalidAggregateAt(
+			"one of equals, min or max is required", node,
+		)
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that accepts either the path to the
+// SnapshotExpect's golden file or a map with `path` and `ignore` fields, and
+// validates the JSONPath expressions in `ignore`.
+func (e *SnapshotExpect) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Value == "" {
+			return InvalidSnapshotAt("path is required", node)
+		}
+		e.Path = node.Value
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "path":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			e.Path = valNode.Value
+		case "ignore":
+			var v *api.FlexStrings
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			for _, path := range v.Values() {
+				if len(path) == 0 || path[0] != '$' {
+					return gdtjson.JSONPathInvalidNoRoot(path, valNode)
+				}
+				if _, err := jsonpath.Parse(path); err != nil {
+					return gdtjson.JSONPathInvalid(path, err, valNode)
+				}
+			}
+			e.Ignore = v.Values()
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if e.Path == "" {
+		return InvalidSnapshotAt("path is required", node)
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that validates the StatusExpect's
+// `code` and `causes` fields.
+func (e *StatusExpect) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "code":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.Atoi(valNode.Value)
+			if err != nil {
+				return parse.ExpectedIntAt(valNode)
+			}
+			if v < 400 || v > 599 {
+				return InvalidStatusCodeAt(v, valNode)
+			}
+			e.Code = v
+		case "reason", "message":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			if key == "reason" {
+				e.Reason = valNode.Value
+			} else {
+				e.Message = valNode.Value
+			}
+		case "causes":
+			if valNode.Kind != yaml.SequenceNode {
+				return parse.ExpectedSequenceAt(valNode)
+			}
+			var v []*StatusCauseExpect
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			e.Causes = v
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that ensures the StatusCauseExpect
+// has at least one field to match on.
+func (e *StatusCauseExpect) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		if valNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(valNode)
+		}
+		switch key {
+		case "field":
+			e.Field = valNode.Value
+		case "type":
+			e.Type = valNode.Value
+		case "message":
+			e.Message = valNode.Value
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if e.Field == "" && e.Type == "" && e.Message == "" {
+		return StatusCauseFieldRequiredAt(node)
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that validates the field paths in the
+// ConflictExpect.
+func (e *ConflictExpect) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "manager":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			e.Manager = valNode.Value
+		case "fields":
+			var v *api.FlexStrings
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			for _, path := range v.Values() {
+				if !strings.HasPrefix(path, ".") {
+					return InvalidFieldPathAt(path, valNode)
+				}
+			}
+			e.Fields = v
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that understands that the value of the
+// ConditionMatch can be either a string, a slice of strings, or an object with .
+func (m *ConditionMatch) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode || node.Kind == yaml.SequenceNode {
+		var fs api.FlexStrings
+		if err := node.Decode(&fs); err != nil {
+			return InvalidConditionMatchAt(err, node)
+		}
+		m.conditionMatch = conditionMatch{Status: &fs}
+	}
+	if node.Kind == yaml.MappingNode {
+		var cm conditionMatch
+		if err := node.Decode(&cm); err != nil {
+			return InvalidConditionMatchAt(err, node)
+		}
+		for i := 0; i < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			valNode := node.Content[i+1]
+			switch keyNode.Value {
+			case "status", "reason", "reason-contains", "message",
+				"message-contains":
+			case "reason-regex", "message-regex":
+				if _, err := regexp.Compile(valNode.Value); err != nil {
+					return InvalidConditionMatchAt(err, valNode)
+				}
+			case "observed-generation":
+				if valNode.Value != ObservedGenerationCurrent {
+					return InvalidConditionMatchAt(fmt.Errorf(
+						"observed-generation must be %q",
+						ObservedGenerationCurrent,
+					), valNode)
+				}
+			case "stable-for":
+				if _, err := time.ParseDuration(valNode.Value); err != nil {
+					return InvalidConditionMatchAt(err, valNode)
+				}
+			default:
+				return parse.UnknownFieldAt(keyNode.Value, keyNode)
+			}
+		}
+		m.conditionMatch = cm
+		return nil
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that understands that the value of the
+// ResourceIdentifier can be either a string or a selector.
+func (r *ResourceIdentifier) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode && node.Kind != yaml.MappingNode {
+		return parse.ExpectedScalarOrMapAt(node)
+	}
+	var s string
+	// A resource identifier can be a string of the form {type}/{name} or
+	// {type}.
+	if err := node.Decode(&s); err == nil {
+		if strings.ContainsAny(s, " ,;\n\t\r") {
+			return InvalidResourceSpecifierAt(s, node)
+		}
+		if strings.Count(s, "/") > 1 {
+			return InvalidResourceSpecifierAt(s, node)
+		}
+		r.Arg, r.Name = splitArgName(s)
+		return nil
+	}
+	// Otherwise the resource identifier should be specified broken out as a
+	// struct with a `type` and `labels` field.
+	var ri resourceIdentifierWithSelector
+	if err := node.Decode(&ri); err != nil {
+		return err
+	}
+	_, err := labels.ValidatedSelectorFromSet(ri.Labels)
+	if err != nil {
+		return InvalidWithLabelsAt(err, node)
+	}
+	r.Arg = ri.Type
+	r.Name = ri.Name
+	r.Labels = ri.Labels
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that understands that the value of the
+// ResourceIdentifierOrFile can be either a string or a selector.
+func (r *ResourceIdentifierOrFile) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode && node.Kind != yaml.MappingNode {
+		return parse.ExpectedScalarOrMapAt(node)
+	}
+	var s string
+	// A resource identifier can be a filepath, a string of the form
+	// {type}/{name} or {type}.
+	if err := node.Decode(&s); err == nil {
+		if probablyFilePath(s) {
+			if !fileExists(s) {
+				return parse.FileNotFoundAt(s, node)
+			}
+			r.fp = s
+			return nil
+		}
+		if strings.ContainsAny(s, " ,;\n\t\r") {
+			return InvalidResourceSpecifierOrFilepathAt(s, node)
+		}
+		if strings.Count(s, "/") > 1 {
+			return InvalidResourceSpecifierOrFilepathAt(s, node)
+		}
+		r.Arg, r.Name = splitArgName(s)
+		return nil
+	}
+	// Otherwise the resource identifier should be specified broken out as a
+	// struct with a `type` and `labels` field.
+	var ri resourceIdentifierWithSelector
+	if err := node.Decode(&ri); err != nil {
+		return err
+	}
+	_, err := labels.ValidatedSelectorFromSet(ri.Labels)
+	if err != nil {
+		return InvalidWithLabelsAt(err, node)
+	}
 	r.Arg = ri.Type
 	r.Name = ri.Name
 	r.Labels = ri.Labels
 	return nil
 }
 
-// UnmarshalYAML is a custom unmarshaler that understands that the value of the
-// ResourceIdentifierOrFile can be either a string or a selector.
-func (r *ResourceIdentifierOrFile) UnmarshalYAML(node *yaml.Node) error {
-	if node.Kind != yaml.ScalarNode && node.Kind != yaml.MappingNode {
-		return parse.ExpectedScalarOrMapAt(node)
+// UnmarshalYAML is a custom unmarshaler that understands that the value of the
+// PatchAction's patch body can be either a string containing a file path or
+// inline YAML/JSON, or an inline object or list.
+func (p *PatchAction) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	var patchNode *yaml.Node
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "target":
+			if valNode.Kind != yaml.ScalarNode && valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedScalarOrMapAt(valNode)
+			}
+			var v *ResourceIdentifier
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			p.Target = v
+		case "type":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			pt := strings.ToLower(valNode.Value)
+			if !lo.Contains(validPatchTypes, pt) {
+				return InvalidPatchTypeAt(valNode.Value, valNode)
+			}
+			p.Type = pt
+		case "patch":
+			patchNode = valNode
+			switch valNode.Kind {
+			case yaml.ScalarNode:
+				if valNode.Tag == "!!null" {
+					return InvalidPatchAt("empty patch body", valNode)
+				}
+				v := valNode.Value
+				if probablyFilePath(v) {
+					if !fileExists(v) {
+						return parse.FileNotFoundAt(v, valNode)
+					}
+				}
+				p.Patch = v
+			case yaml.MappingNode, yaml.SequenceNode:
+				b, err := yaml.Marshal(valNode)
+				if err != nil {
+					return InvalidPatchAt(err.Error(), valNode)
+				}
+				p.Patch = string(b)
+			default:
+				return InvalidPatchAt("expected string, object or list", valNode)
+			}
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if p.Target == nil {
+		return PatchFieldRequiredAt("target", node)
+	}
+	if patchNode == nil {
+		return PatchFieldRequiredAt("patch", node)
+	}
+	if !probablyFilePath(p.Patch) {
+		// check the inline patch body can be unmarshaled and that JSON
+		// patches are a list of operations.
+		var v any
+		if err := yaml.Unmarshal([]byte(p.Patch), &v); err != nil {
+			return InvalidPatchAt(err.Error(), patchNode)
+		}
+		switch v.(type) {
+		case []any:
+			if p.Type != PatchTypeJSON {
+				return InvalidPatchAt(
+					"a list of operations is only valid for `json` patches",
+					patchNode,
+				)
+			}
+		case map[string]any:
+			if p.Type == PatchTypeJSON {
+				return InvalidPatchAt(
+					"`json` patches must be a list of operations",
+					patchNode,
+				)
+			}
+		default:
+			return InvalidPatchAt("expected object or list", patchNode)
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that understands that the
+// ExecAction's target may be a bare Pod name and that the command may be
+// either a string or a list of strings.
+func (e *ExecAction) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "target":
+			v, err := podTargetFromNode(valNode)
+			if err != nil {
+				return err
+			}
+			e.Target = v
+		case "container":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			e.Container = valNode.Value
+		case "command":
+			switch valNode.Kind {
+			case yaml.ScalarNode:
+				args, err := shlex.Split(valNode.Value)
+				if err != nil {
+					return InvalidExecCommandAt(err, valNode)
+				}
+				e.Command = args
+			case yaml.SequenceNode:
+				var args []string
+				if err := valNode.Decode(&args); err != nil {
+					return InvalidExecCommandAt(err, valNode)
+				}
+				e.Command = args
+			default:
+				return parse.ExpectedScalarOrSequenceAt(valNode)
+			}
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if e.Target == nil {
+		return ExecFieldRequiredAt("target", node)
+	}
+	if len(e.Command) == 0 {
+		return ExecFieldRequiredAt("command", node)
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that understands that the
+// LogsAction's target may be a bare Pod name and validates the `since`
+// duration.
+func (l *LogsAction) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "target":
+			v, err := podTargetFromNode(valNode)
+			if err != nil {
+				return err
+			}
+			l.Target = v
+		case "container":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			l.Container = valNode.Value
+		case "all-containers", "previous":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.ParseBool(valNode.Value)
+			if err != nil {
+				return parse.ExpectedBoolAt(valNode)
+			}
+			if key == "previous" {
+				l.Previous = v
+			} else {
+				l.AllContainers = v
+			}
+		case "since":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			since, err := time.ParseDuration(valNode.Value)
+			if err != nil {
+				return InvalidDurationAt(err, valNode)
+			}
+			// The Kubernetes API server only accepts a whole, positive number
+			// of seconds for sinceSeconds.
+			if since < time.Second {
+				return InvalidDurationAt(
+					fmt.Errorf("since must be at least 1s but got %s", since),
+					valNode,
+				)
+			}
+			l.Since = valNode.Value
+		case "tail":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.ParseInt(valNode.Value, 10, 64)
+			if err != nil {
+				return parse.ExpectedIntAt(valNode)
+			}
+			l.Tail = &v
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if l.Target == nil {
+		return LogsFieldRequiredAt("target", node)
+	}
+	if l.AllContainers && l.Container != "" {
+		return LogsContainerConflictAt(node)
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that validates the regular
+// expressions contained in the PipeExpect.
+func (e *PipeExpect) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "contains", "contains-none-of", "contains-one-of", "regex":
+			var v *api.FlexStrings
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			switch key {
+			case "contains":
+				e.ContainsAll = v
+			case "contains-none-of":
+				e.ContainsNone = v
+			case "contains-one-of":
+				e.ContainsAny = v
+			case "regex":
+				for _, expr := range v.Values() {
+					if _, err := regexp.Compile(expr); err != nil {
+						return InvalidRegexAt(expr, err, valNode)
+					}
+				}
+				e.Regex = v
+			}
+		case "lines":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.Atoi(valNode.Value)
+			if err != nil {
+				return parse.ExpectedIntAt(valNode)
+			}
+			e.Lines = &v
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	return nil
+}
+
+// podTargetFromNode decodes a resource identifier for an action that targets
+// Pods. A bare string (with no `/` separator) is the name of a Pod, just like
+// the argument to `kubectl exec` or `kubectl logs`.
+func podTargetFromNode(node *yaml.Node) (*ResourceIdentifier, error) {
+	if node.Kind != yaml.ScalarNode && node.Kind != yaml.MappingNode {
+		return nil, parse.ExpectedScalarOrMapAt(node)
+	}
+	var v *ResourceIdentifier
+	if err := node.Decode(&v); err != nil {
+		return nil, err
+	}
+	if node.Kind == yaml.ScalarNode && v.Name == "" {
+		v.Name = v.Arg
+		v.Arg = "pods"
+	}
+	return v, nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that validates the WaitAction's `for`
+// field.
+func (w *WaitAction) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "target":
+			if valNode.Kind != yaml.ScalarNode && valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedScalarOrMapAt(valNode)
+			}
+			var v *ResourceIdentifier
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			w.Target = v
+		case "for":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			if _, err := parseWaitFor(valNode.Value); err != nil {
+				return InvalidWaitForAt(valNode.Value, err, valNode)
+			}
+			w.For = valNode.Value
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if w.Target == nil {
+		return WaitFieldRequiredAt("target", node)
+	}
+	if w.For == "" {
+		return WaitFieldRequiredAt("for", node)
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that validates the WatchAction's
+// `duration` and `until` fields.
+func (w *WatchAction) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
 	}
-	var s string
-	// A resource identifier can be a filepath, a string of the form
-	// {type}/{name} or {type}.
-	if err := node.Decode(&s); err == nil {
-		if probablyFilePath(s) {
-			if !fileExists(s) {
-				return parse.FileNotFoundAt(s, node)
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "target":
+			if valNode.Kind != yaml.ScalarNode && valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedScalarOrMapAt(valNode)
 			}
-			r.fp = s
-			return nil
+			var v *ResourceIdentifier
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			w.Target = v
+		case "duration":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			if _, err := time.ParseDuration(valNode.Value); err != nil {
+				return InvalidDurationAt(err, valNode)
+			}
+			w.Duration = valNode.Value
+		case "until":
+			if valNode.Kind == yaml.ScalarNode && valNode.Value == WatchUntilDeleted {
+				w.Until = WatchUntilDeleted
+				continue
+			}
+			v, err := matchesFromNode(valNode)
+			if err != nil {
+				return err
+			}
+			w.Until = v
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
 		}
-		if strings.ContainsAny(s, " ,;\n\t\r") {
-			return InvalidResourceSpecifierOrFilepathAt(s, node)
+	}
+	if w.Target == nil {
+		return WatchFieldRequiredAt("target", node)
+	}
+	if w.Duration == "" && w.Until == nil {
+		return WatchDurationOrUntilRequiredAt(node)
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that validates the `matches`
+// fragments in the WatchExpect.
+func (e *WatchExpect) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
 		}
-		if strings.Count(s, "/") > 1 {
-			return InvalidResourceSpecifierOrFilepathAt(s, node)
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "sequence", "never":
+			if valNode.Kind != yaml.SequenceNode {
+				return parse.ExpectedSequenceAt(valNode)
+			}
+			fragments := make([]any, len(valNode.Content))
+			for x, fragNode := range valNode.Content {
+				v, err := matchesFromNode(fragNode)
+				if err != nil {
+					return err
+				}
+				fragments[x] = v
+			}
+			if key == "sequence" {
+				e.Sequence = fragments
+			} else {
+				e.Never = fragments
+			}
+		case "added", "modified", "deleted":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.Atoi(valNode.Value)
+			if err != nil {
+				return parse.ExpectedIntAt(valNode)
+			}
+			switch key {
+			case "added":
+				e.Added = &v
+			case "modified":
+				e.Modified = &v
+			case "deleted":
+				e.Deleted = &v
+			}
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
 		}
-		r.Arg, r.Name = splitArgName(s)
-		return nil
 	}
-	// Otherwise the resource identifier should be specified broken out as a
-	// struct with a `type` and `labels` field.
-	var ri resourceIdentifierWithSelector
-	if err := node.Decode(&ri); err != nil {
-		return err
+	return nil
+}
+
+// matchesFromNode decodes a `matches` fragment, which may be a
+// map[string]any, an inline YAML string or a file path to a YAML manifest.
+func matchesFromNode(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		var v map[string]interface{}
+		if err := node.Decode(&v); err != nil {
+			return nil, err
+		}
+		if err := validateListDirectives(v); err != nil {
+			return nil, InvalidListDirectiveAt(err, node)
+		}
+		if err := validateOperators(v); err != nil {
+			return nil, InvalidOperatorAt(err, node)
+		}
+		return v, nil
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return nil, parse.ExpectedMapOrYAMLStringAt(node)
+		}
+		var v string
+		if err := node.Decode(&v); err != nil {
+			return nil, err
+		}
+		if probablyFilePath(v) {
+			if !fileExists(v) {
+				return nil, parse.FileNotFoundAt(v, node)
+			}
+			// Check the file's content can be unmarshaled into a
+			// map[string]interface{}. We return the file path because any
+			// variable references in the content are replaced when the
+			// assertion is evaluated.
+			b, err := os.ReadFile(v)
+			if err != nil {
+				return nil, err
+			}
+			content := parse.ExpandWithFixedDoubleDollar(string(b))
+			var m map[string]interface{}
+			if err := yaml.Unmarshal([]byte(content), &m); err != nil {
+				return nil, InvalidMatchesUnmarshalErrorAt(err, node)
+			}
+			return v, nil
+		}
+		// inline YAML. check it can be unmarshaled into a
+		// map[string]interface{}
+		var m map[string]interface{}
+		if err := yaml.Unmarshal([]byte(v), &m); err != nil {
+			return nil, InvalidMatchesUnmarshalErrorAt(err, node)
+		}
+		if err := validateListDirectives(m); err != nil {
+			return nil, InvalidListDirectiveAt(err, node)
+		}
+		if err := validateOperators(m); err != nil {
+			return nil, InvalidOperatorAt(err, node)
+		}
+		return m, nil
 	}
-	_, err := labels.ValidatedSelectorFromSet(ri.Labels)
-	if err != nil {
-		return InvalidWithLabelsAt(err, node)
+	return nil, parse.ExpectedMapOrYAMLStringAt(node)
+}
+
+// isConditionsAnyAllNode returns true if the supplied `conditions` node is a
+// mapping with only `any` and `all` keys, instead of a mapping keyed by
+// ConditionType.
+func isConditionsAnyAllNode(node *yaml.Node) bool {
+	if len(node.Content) == 0 {
+		return false
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if !lo.Contains([]string{"any", "all"}, node.Content[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// listMatchesKeys are the keys of a `ListMatches` object
+var listMatchesKeys = []string{"any", "all", "none", "exactly"}
+
+// isListMatchesNode returns true if the supplied `matches` node is a mapping
+// with any of the `ListMatches` keys. No Kubernetes object has these
+// top-level fields.
+func isListMatchesNode(node *yaml.Node) bool {
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if lo.Contains(listMatchesKeys, node.Content[i].Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalYAML is a custom unmarshaler that decodes the ListMatches'
+// `matches` fragments.
+func (m *ListMatches) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "any", "all", "none":
+			v, err := matchesFromNode(valNode)
+			if err != nil {
+				return err
+			}
+			switch key {
+			case "any":
+				m.Any = v
+			case "all":
+				m.All = v
+			case "none":
+				m.None = v
+			}
+		case "exactly":
+			if valNode.Kind != yaml.SequenceNode {
+				return parse.ExpectedSequenceAt(valNode)
+			}
+			fragments := make([]any, len(valNode.Content))
+			for x, fragNode := range valNode.Content {
+				v, err := matchesFromNode(fragNode)
+				if err != nil {
+					return err
+				}
+				if fm, ok := v.(map[string]any); ok {
+					if _, name := matchObjectIdentity(fm); name == "" {
+						return MatchesNameRequiredAt(fragNode)
+					}
+				}
+				fragments[x] = v
+			}
+			m.Exactly = fragments
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
 	}
-	r.Arg = ri.Type
-	r.Name = ri.Name
-	r.Labels = ri.Labels
 	return nil
 }
 
+// describeTargetFromNode decodes the resource identifier for a
+// `kube.describe` action, ensuring that a single named resource is
+// identified.
+func describeTargetFromNode(node *yaml.Node) (*ResourceIdentifier, error) {
+	var v *ResourceIdentifier
+	if err := node.Decode(&v); err != nil {
+		return nil, err
+	}
+	if v.Name == "" {
+		return nil, DescribeNameRequiredAt(node)
+	}
+	return v, nil
+}
+
 // UnmarshalYAML is a custom unmarshaler that ensures that JSONPath expressions
 // contained in the VarEntry are valid.
 func (e *VarEntry) UnmarshalYAML(node *yaml.Node) error {
@@ -649,6 +2509,120 @@ func (e *VarEntry) UnmarshalYAML(node *yaml.Node) error {
 	return nil
 }
 
+// UnmarshalYAML is a custom unmarshaler that understands that the value of
+// the EventsAction can be either a resource identifier string or an object
+// with `target` and `since` fields.
+func (e *EventsAction) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var v *ResourceIdentifier
+		if err := node.Decode(&v); err != nil {
+			return err
+		}
+		e.Target = v
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedScalarOrMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "target":
+			if valNode.Kind != yaml.ScalarNode && valNode.Kind != yaml.MappingNode {
+				return parse.ExpectedScalarOrMapAt(valNode)
+			}
+			var v *ResourceIdentifier
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			e.Target = v
+		case "since":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			if _, err := time.ParseDuration(valNode.Value); err != nil {
+				return InvalidDurationAt(err, valNode)
+			}
+			e.Since = valNode.Value
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	if e.Target == nil {
+		return EventsFieldRequiredAt("target", node)
+	}
+	return nil
+}
+
+// UnmarshalYAML is a custom unmarshaler that validates the EventsExpect's
+// `type` and `count` fields.
+func (e *EventsExpect) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return parse.ExpectedMapAt(node)
+	}
+	// maps/structs are stored in a top-level Node.Content field which is a
+	// concatenated slice of Node pointers in pairs of key/values.
+	for i := 0; i < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		if keyNode.Kind != yaml.ScalarNode {
+			return parse.ExpectedScalarAt(keyNode)
+		}
+		key := keyNode.Value
+		valNode := node.Content[i+1]
+		switch key {
+		case "has-reason":
+			var v *api.FlexStrings
+			if err := valNode.Decode(&v); err != nil {
+				return err
+			}
+			e.HasReason = v
+		case "no-warnings":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.ParseBool(valNode.Value)
+			if err != nil {
+				return parse.ExpectedBoolAt(valNode)
+			}
+			e.NoWarnings = v
+		case "type":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			typ := valNode.Value
+			if !strings.EqualFold(typ, EventTypeNormal) &&
+				!strings.EqualFold(typ, EventTypeWarning) {
+				return InvalidEventTypeAt(typ, valNode)
+			}
+			e.Type = typ
+		case "count":
+			if valNode.Kind != yaml.ScalarNode {
+				return parse.ExpectedScalarAt(valNode)
+			}
+			v, err := strconv.Atoi(valNode.Value)
+			if err != nil {
+				return parse.ExpectedIntAt(valNode)
+			}
+			e.Count = &v
+		default:
+			return parse.UnknownFieldAt(key, keyNode)
+		}
+	}
+	return nil
+}
+
+// validDryRun returns true if the supplied string is a known `dry-run` mode
+func validDryRun(mode string) bool {
+	return mode == DryRunServer || mode == DryRunNone
+}
+
 // moreThanOneAction returns true if the test author has specified more than a
 // single action in the KubeSpec.
 func moreThanOneAction(a *Action) bool {
@@ -665,6 +2639,27 @@ func moreThanOneAction(a *Action) bool {
 	if a.Delete != nil {
 		foundActions += 1
 	}
+	if a.Patch != nil {
+		foundActions += 1
+	}
+	if a.Exec != nil {
+		foundActions += 1
+	}
+	if a.Logs != nil {
+		foundActions += 1
+	}
+	if a.Describe != nil {
+		foundActions += 1
+	}
+	if a.Wait != nil {
+		foundActions += 1
+	}
+	if a.Watch != nil {
+		foundActions += 1
+	}
+	if a.Events != nil {
+		foundActions += 1
+	}
 	return foundActions > 1
 }
 
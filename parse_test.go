@@ -241,66 +241,514 @@ func TestFailureBadPlacementNotObject(t *testing.T) {
 	require.Nil(s)
 }
 
+func TestFailureBadPlacementMaxSkew(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-placement-max-skew.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "invalid max-skew 0")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadPlacementTaint(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-placement-taint.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, `unknown taint effect "NoRun"`)
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadPlacementAffinityNoTarget(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-placement-affinity-no-target.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "`assert.placement.anti-affinity.target` is required")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadMatchesExactlyNoName(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-matches-exactly-no-name.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "items must have a `metadata.name`")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadMatchesListDirective(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-matches-list-directive.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "only one of $subset, $unordered, $merge may be used")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadMatchesOperator(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-matches-operator.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "$gte must be a number or resource quantity")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadCEL(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-cel.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "invalid `kube.assert.cel` expression")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadReady(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-ready.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "expected `kube.assert.ready` to be true, false")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadConditionStableFor(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-condition-stable-for.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "`kube.assert.conditions` not well-formed")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadAggregate(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-aggregate.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "exactly one of count or sum is required")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadSnapshotIgnore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-snapshot-ignore.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "expression must start with")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
 func TestWithLabelsInvalid(t *testing.T) {
 	assert := assert.New(t)
 	require := require.New(t)
 
-	fp := filepath.Join("testdata", "parse", "fail", "with-labels-invalid.yaml")
+	fp := filepath.Join("testdata", "parse", "fail", "with-labels-invalid.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "with labels invalid")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadVarType(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-var-type.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "expected map")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadVarJSONPathNoRoot(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-var-jsonpath-noroot.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "expression must start with")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadVarJSONPath(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-var-jsonpath.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "JSONPath invalid")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadPatchType(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-patch-type.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "invalid patch type")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadPatchJSONNotList(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-patch-json-not-list.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "`json` patches must be a list of operations")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadPatchNoTarget(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-patch-no-target.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "`kube.patch.target` is required")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadExecNoCommand(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-exec-no-command.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "`kube.exec.command` is required")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadLogsRegex(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-logs-regex.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "invalid regular expression")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadLogsSince(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-logs-since.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "invalid duration")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadLogsSinceSubsecond(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-logs-since-subsecond.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "since must be at least 1s")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadWatchDurationExceedsTimeout(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-watch-duration-exceeds-timeout.yaml")
 	f, err := os.Open(fp)
 	require.Nil(err)
 	defer f.Close() // nolint:errcheck
 
 	s, err := scenario.FromReader(f, scenario.WithPath(fp))
 	require.NotNil(err)
-	assert.ErrorContains(err, "with labels invalid")
+	assert.ErrorContains(err, "kube.watch duration 30s must be less than the test spec's timeout of 10s")
 	assert.Error(err, &parse.Error{})
 	require.Nil(s)
 }
 
-func TestFailureBadVarType(t *testing.T) {
+func TestFailureBadWatchDurationExceedsTimeoutMargin(t *testing.T) {
 	assert := assert.New(t)
 	require := require.New(t)
 
-	fp := filepath.Join("testdata", "parse", "fail", "bad-var-type.yaml")
+	fp := filepath.Join("testdata", "parse", "fail", "bad-watch-duration-exceeds-timeout-margin.yaml")
 	f, err := os.Open(fp)
 	require.Nil(err)
 	defer f.Close() // nolint:errcheck
 
 	s, err := scenario.FromReader(f, scenario.WithPath(fp))
 	require.NotNil(err)
-	assert.ErrorContains(err, "expected map")
+	assert.ErrorContains(err, "kube.watch duration 9800ms must be less than the test spec's timeout of 10s")
 	assert.Error(err, &parse.Error{})
 	require.Nil(s)
 }
 
-func TestFailureBadVarJSONPathNoRoot(t *testing.T) {
+func TestFailureBadDescribeNoName(t *testing.T) {
 	assert := assert.New(t)
 	require := require.New(t)
 
-	fp := filepath.Join("testdata", "parse", "fail", "bad-var-jsonpath-noroot.yaml")
+	fp := filepath.Join("testdata", "parse", "fail", "bad-describe-no-name.yaml")
 	f, err := os.Open(fp)
 	require.Nil(err)
 	defer f.Close() // nolint:errcheck
 
 	s, err := scenario.FromReader(f, scenario.WithPath(fp))
 	require.NotNil(err)
-	assert.ErrorContains(err, "expression must start with")
+	assert.ErrorContains(err, "`kube.describe` requires a resource name")
 	assert.Error(err, &parse.Error{})
 	require.Nil(s)
 }
 
-func TestFailureBadVarJSONPath(t *testing.T) {
+func TestFailureBadWaitFor(t *testing.T) {
 	assert := assert.New(t)
 	require := require.New(t)
 
-	fp := filepath.Join("testdata", "parse", "fail", "bad-var-jsonpath.yaml")
+	fp := filepath.Join("testdata", "parse", "fail", "bad-wait-for.yaml")
 	f, err := os.Open(fp)
 	require.Nil(err)
 	defer f.Close() // nolint:errcheck
 
 	s, err := scenario.FromReader(f, scenario.WithPath(fp))
 	require.NotNil(err)
-	assert.ErrorContains(err, "JSONPath invalid")
+	assert.ErrorContains(err, "invalid `kube.wait.for` value")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadWatchNoStop(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-watch-no-stop.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "either `kube.watch.duration` or `kube.watch.until` is required")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadEventsType(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-events-type.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "invalid event type \"Error\"")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadDryRun(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-dry-run.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "invalid dry-run \"client\"")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadDefaultsDryRun(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-defaults-dry-run.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "invalid dry-run \"all\"")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadManagedFieldsPath(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-managed-fields-path.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "invalid field path \"spec.replicas\"")
+	assert.Error(err, &parse.Error{})
+	require.Nil(s)
+}
+
+func TestFailureBadStatusCode(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "parse", "fail", "bad-status-code.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+	defer f.Close() // nolint:errcheck
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.NotNil(err)
+	assert.ErrorContains(err, "invalid status code 200")
 	assert.Error(err, &parse.Error{})
 	require.Nil(s)
 }
@@ -330,170 +778,784 @@ spec:
      image: nginx:1.7.9
 `
 	var zero int
+	logsTail := int64(10)
+	eventsCount := 2
+	noForce := false
+	failOnDeprecation := true
+	warningsCount := 0
+	maxPodsPerNode := 1
+	lenMin := 2
+	lenMax := 5
 
 	expTests := []api.Evaluable{
 		&gdtkube.Spec{
 			Spec: api.Spec{
-				Index:    0,
-				Name:     "create a pod from YAML using kube.create shortcut",
+				Index:    0,
+				Name:     "create a pod from YAML using kube.create shortcut",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Create: podYAML,
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    1,
+				Name:     "apply a pod from a file using kube.apply shortcut",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Apply: "manifests/nginx-pod.yaml",
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    2,
+				Name:     "create a pod from YAML",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Create: podYAML,
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    3,
+				Name:     "delete a pod from a file",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Delete: gdtkube.NewResourceIdentifierOrFile(
+						"manifests/nginx-pod.yaml",
+						"", "", nil,
+					),
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    4,
+				Name:     "fetch a pod via kube.get shortcut",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Get: gdtkube.NewResourceIdentifier(
+						"pods", "name", nil,
+					),
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    5,
+				Name:     "fetch a pod via long-form kube:get",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Get: gdtkube.NewResourceIdentifier(
+						"pods", "name", nil,
+					),
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    6,
+				Name:     "fetch a pod via kube.get shortcut to long-form resource identifier with labels",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Get: gdtkube.NewResourceIdentifier(
+						"pods", "", map[string]string{
+							"app": "nginx",
+						},
+					),
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    7,
+				Name:     "fetch a pod via kube:get long-form resource identifier with labels",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Get: gdtkube.NewResourceIdentifier(
+						"pods", "", map[string]string{
+							"app": "nginx",
+						},
+					),
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    8,
+				Name:     "fetch a pod with envvar substitution",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Get: gdtkube.NewResourceIdentifier(
+						"pods", "foo", nil,
+					),
+				},
+			},
+			Assert: &gdtkube.Expect{
+				Len: &zero,
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    9,
+				Name:     "define a gdt variable",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Get: gdtkube.NewResourceIdentifier(
+						"pods", "name", nil,
+					),
+				},
+			},
+			Var: gdtkube.Variables{
+				"POD": gdtkube.VarEntry{
+					From: "$.metadata.name",
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    10,
+				Name:     "fetch a pod with gdt variable system substitution",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Get: gdtkube.NewResourceIdentifier(
+						"pods",
+						"$POD", // $$POD is replaced with $POD after envvar substitution...
+						nil,
+					),
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    11,
+				Name:     "patch a deployment via kube.patch shortcut",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Patch: gdtkube.NewPatchAction(
+						gdtkube.NewResourceIdentifier(
+							"deployments", "nginx", nil,
+						),
+						"",
+						"spec:\n    replicas: 1\n",
+					),
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    12,
+				Name:     "patch deployments via long-form kube:patch with labels and JSON patch",
 				Defaults: &api.Defaults{},
 			},
 			Kube: &gdtkube.KubeSpec{
 				Action: gdtkube.Action{
-					Create: podYAML,
+					Patch: gdtkube.NewPatchAction(
+						gdtkube.NewResourceIdentifier(
+							"deployments", "", map[string]string{
+								"app": "nginx",
+							},
+						),
+						"json",
+						"- op: replace\n  path: /spec/replicas\n  value: 1\n",
+					),
 				},
 			},
 		},
 		&gdtkube.Spec{
 			Spec: api.Spec{
-				Index:    1,
-				Name:     "apply a pod from a file using kube.apply shortcut",
+				Index:    13,
+				Name:     "exec a command in a pod via kube.exec shortcut",
 				Defaults: &api.Defaults{},
 			},
 			Kube: &gdtkube.KubeSpec{
 				Action: gdtkube.Action{
-					Apply: "manifests/nginx-pod.yaml",
+					Exec: gdtkube.NewExecAction(
+						gdtkube.NewResourceIdentifier(
+							"pods", "nginx", nil,
+						),
+						"",
+						[]string{"curl", "-s", "http://localhost:80/"},
+					),
 				},
 			},
 		},
 		&gdtkube.Spec{
 			Spec: api.Spec{
-				Index:    2,
-				Name:     "create a pod from YAML",
+				Index:    14,
+				Name:     "exec a command in a deployment's pod via long-form kube:exec",
 				Defaults: &api.Defaults{},
 			},
 			Kube: &gdtkube.KubeSpec{
 				Action: gdtkube.Action{
-					Create: podYAML,
+					Exec: gdtkube.NewExecAction(
+						gdtkube.NewResourceIdentifier(
+							"deployments", "nginx", nil,
+						),
+						"nginx",
+						[]string{"ls", "/"},
+					),
 				},
 			},
 		},
 		&gdtkube.Spec{
 			Spec: api.Spec{
-				Index:    3,
-				Name:     "delete a pod from a file",
+				Index:    15,
+				Name:     "fetch logs from a pod via kube.logs shortcut",
 				Defaults: &api.Defaults{},
 			},
 			Kube: &gdtkube.KubeSpec{
 				Action: gdtkube.Action{
-					Delete: gdtkube.NewResourceIdentifierOrFile(
-						"manifests/nginx-pod.yaml",
-						"", "", nil,
+					Logs: &gdtkube.LogsAction{
+						Target: gdtkube.NewResourceIdentifier(
+							"pods", "nginx", nil,
+						),
+						Tail: &logsTail,
+					},
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    16,
+				Name:     "fetch logs from all containers in a deployment's pods via long-form kube:logs",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Logs: &gdtkube.LogsAction{
+						Target: gdtkube.NewResourceIdentifier(
+							"deployments", "nginx", nil,
+						),
+						AllContainers: true,
+						Since:         "5m",
+					},
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    17,
+				Name:     "describe a deployment via kube.describe shortcut",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Describe: gdtkube.NewResourceIdentifier(
+						"deployments", "nginx", nil,
 					),
 				},
 			},
 		},
 		&gdtkube.Spec{
 			Spec: api.Spec{
-				Index:    4,
-				Name:     "fetch a pod via kube.get shortcut",
+				Index:    18,
+				Name:     "describe a pod via long-form kube:describe resource identifier",
 				Defaults: &api.Defaults{},
 			},
 			Kube: &gdtkube.KubeSpec{
 				Action: gdtkube.Action{
-					Get: gdtkube.NewResourceIdentifier(
-						"pods", "name", nil,
+					Describe: gdtkube.NewResourceIdentifier(
+						"pods", "nginx", nil,
 					),
 				},
 			},
 		},
 		&gdtkube.Spec{
 			Spec: api.Spec{
-				Index:    5,
-				Name:     "fetch a pod via long-form kube:get",
+				Index:    19,
+				Name:     "wait for a pod condition via kube.wait shortcut",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Wait: gdtkube.NewWaitAction(
+						gdtkube.NewResourceIdentifier(
+							"pods", "nginx", nil,
+						),
+						"condition=Ready",
+					),
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    20,
+				Name:     "wait for labelled pods to have a JSONPath value via long-form kube:wait",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Wait: gdtkube.NewWaitAction(
+						gdtkube.NewResourceIdentifier(
+							"pods", "",
+							map[string]string{
+								"app": "nginx",
+							},
+						),
+						"jsonpath='{.status.phase}'=Running",
+					),
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    21,
+				Name:     "watch a pod until it is deleted via kube.watch shortcut",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Watch: gdtkube.NewWatchAction(
+						gdtkube.NewResourceIdentifier(
+							"pods", "nginx", nil,
+						),
+						"30s",
+						gdtkube.WatchUntilDeleted,
+					),
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Index:    22,
+				Name:     "watch labelled pods until one succeeds via long-form kube:watch",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Watch: gdtkube.NewWatchAction(
+						gdtkube.NewResourceIdentifier(
+							"pods", "",
+							map[string]string{
+								"app": "nginx",
+							},
+						),
+						"",
+						map[string]any{
+							"status": map[string]any{
+								"phase": "Succeeded",
+							},
+						},
+					),
+				},
+			},
+			Assert: &gdtkube.Expect{
+				Watch: &gdtkube.WatchExpect{
+					Sequence: []any{
+						map[string]any{
+							"status": map[string]any{
+								"phase": "Running",
+							},
+						},
+						map[string]any{
+							"status": map[string]any{
+								"phase": "Succeeded",
+							},
+						},
+					},
+					Never: []any{
+						map[string]any{
+							"status": map[string]any{
+								"phase": "Failed",
+							},
+						},
+					},
+					Deleted: &zero,
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Plugin:   gdtkube.Plugin(),
+				Index:    23,
+				Name:     "fetch events for a pod via kube.events shortcut",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Events: gdtkube.NewEventsAction(
+						gdtkube.NewResourceIdentifier("pods", "nginx", nil),
+						"",
+					),
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Plugin:   gdtkube.Plugin(),
+				Index:    24,
+				Name:     "fetch recent events for labelled pods via long-form kube:events",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Events: gdtkube.NewEventsAction(
+						gdtkube.NewResourceIdentifier(
+							"pods", "",
+							map[string]string{
+								"app": "nginx",
+							},
+						),
+						"5m",
+					),
+				},
+			},
+			Assert: &gdtkube.Expect{
+				Events: &gdtkube.EventsExpect{
+					Type:       "Normal",
+					NoWarnings: true,
+					Count:      &eventsCount,
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Plugin:   gdtkube.Plugin(),
+				Index:    25,
+				Name:     "match a configmap against a matches file",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Get: gdtkube.NewResourceIdentifier("configmaps", "nginx", nil),
+				},
+			},
+			Assert: &gdtkube.Expect{
+				Matches: "matches/configmap-vars.yaml",
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Plugin:   gdtkube.Plugin(),
+				Index:    26,
+				Name:     "create objects and match them by index",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Create: "manifests/nginx-pod.yaml",
+				},
+			},
+			Assert: &gdtkube.Expect{
+				Matches: []any{
+					map[string]any{
+						"kind": "Pod",
+						"metadata": map[string]any{
+							"name": "nginx",
+						},
+					},
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Plugin:   gdtkube.Plugin(),
+				Index:    27,
+				Name:     "create a pod in server-side dry-run mode",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Create: "manifests/nginx-pod.yaml",
+				},
+				DryRun: "server",
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Plugin:   gdtkube.Plugin(),
+				Index:    28,
+				Name:     "apply without forcing conflicts as a different field manager",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Apply: "manifests/nginx-deployment.yaml",
+				},
+				FieldManager: "my-user",
+				Force:        &noForce,
+			},
+			Assert: &gdtkube.Expect{
+				Conflict: &gdtkube.ConflictExpect{
+					Manager: "my-controller",
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Plugin:   gdtkube.Plugin(),
+				Index:    29,
+				Name:     "apply as a different field manager and check field ownership",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Apply: "manifests/nginx-deployment.yaml",
+				},
+				FieldManager: "my-user",
+			},
+			Assert: &gdtkube.Expect{
+				ManagedFields: map[string]string{
+					".spec.replicas": "my-user",
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Plugin:   gdtkube.Plugin(),
+				Index:    30,
+				Name:     "create a pod that the API server rejects",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Create: "manifests/nginx-pod.yaml",
+				},
+			},
+			Assert: &gdtkube.Expect{
+				Status: &gdtkube.StatusExpect{
+					Code:   409,
+					Reason: "AlreadyExists",
+					Causes: []*gdtkube.StatusCauseExpect{
+						{
+							Field:   "metadata.name",
+							Message: "already exists",
+						},
+					},
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Plugin:   gdtkube.Plugin(),
+				Index:    31,
+				Name:     "get pods without any deprecation warning",
 				Defaults: &api.Defaults{},
 			},
 			Kube: &gdtkube.KubeSpec{
 				Action: gdtkube.Action{
 					Get: gdtkube.NewResourceIdentifier(
-						"pods", "name", nil,
+						"pods", "", nil,
 					),
 				},
+				FailOnDeprecation: &failOnDeprecation,
+			},
+			Assert: &gdtkube.Expect{
+				Warnings: &gdtkube.WarningsExpect{
+					Count: &warningsCount,
+				},
 			},
 		},
 		&gdtkube.Spec{
 			Spec: api.Spec{
-				Index:    6,
-				Name:     "fetch a pod via kube.get shortcut to long-form resource identifier with labels",
+				Plugin:   gdtkube.Plugin(),
+				Index:    32,
+				Name:     "check the placement constraints of a deployment's pods",
 				Defaults: &api.Defaults{},
 			},
 			Kube: &gdtkube.KubeSpec{
 				Action: gdtkube.Action{
 					Get: gdtkube.NewResourceIdentifier(
-						"pods", "", map[string]string{
-							"app": "nginx",
-						},
+						"deployments", "nginx", nil,
 					),
 				},
 			},
+			Assert: &gdtkube.Expect{
+				Placement: &gdtkube.PlacementAssertion{
+					NodeSelector: map[string]string{
+						"kubernetes.io/os": "linux",
+					},
+					AntiAffinity: &gdtkube.PlacementAffinity{
+						Target: gdtkube.NewResourceIdentifier(
+							"deployments", "cache", nil,
+						),
+					},
+					MaxPodsPerNode: &maxPodsPerNode,
+				},
+			},
 		},
 		&gdtkube.Spec{
 			Spec: api.Spec{
-				Index:    7,
-				Name:     "fetch a pod via kube:get long-form resource identifier with labels",
+				Plugin:   gdtkube.Plugin(),
+				Index:    33,
+				Name:     "match the items of a list of pods",
 				Defaults: &api.Defaults{},
 			},
 			Kube: &gdtkube.KubeSpec{
 				Action: gdtkube.Action{
 					Get: gdtkube.NewResourceIdentifier(
-						"pods", "", map[string]string{
-							"app": "nginx",
-						},
+						"pods", "", nil,
 					),
 				},
 			},
+			Assert: &gdtkube.Expect{
+				Matches: &gdtkube.ListMatches{
+					Any: map[string]any{
+						"status": map[string]any{
+							"phase": "Running",
+						},
+					},
+					Exactly: []any{
+						map[string]any{
+							"metadata": map[string]any{
+								"name": "nginx",
+							},
+						},
+					},
+				},
+			},
 		},
 		&gdtkube.Spec{
 			Spec: api.Spec{
-				Index:    8,
-				Name:     "fetch a pod with envvar substitution",
+				Plugin:   gdtkube.Plugin(),
+				Index:    34,
+				Name:     "match a pod's containers by name",
 				Defaults: &api.Defaults{},
 			},
 			Kube: &gdtkube.KubeSpec{
 				Action: gdtkube.Action{
 					Get: gdtkube.NewResourceIdentifier(
-						"pods", "foo", nil,
+						"pods", "nginx", nil,
 					),
 				},
 			},
 			Assert: &gdtkube.Expect{
-				Len: &zero,
+				Matches: map[string]any{
+					"spec": map[string]any{
+						"containers": map[string]any{
+							"$merge": []any{
+								map[string]any{
+									"name":  "nginx",
+									"image": "nginx",
+								},
+							},
+						},
+					},
+				},
 			},
 		},
 		&gdtkube.Spec{
 			Spec: api.Spec{
-				Index:    9,
-				Name:     "define a gdt variable",
+				Plugin:   gdtkube.Plugin(),
+				Index:    35,
+				Name:     "match a deployment's fields using operators",
 				Defaults: &api.Defaults{},
 			},
 			Kube: &gdtkube.KubeSpec{
 				Action: gdtkube.Action{
 					Get: gdtkube.NewResourceIdentifier(
-						"pods", "name", nil,
+						"deployments", "nginx", nil,
 					),
 				},
 			},
-			Var: gdtkube.Variables{
-				"POD": gdtkube.VarEntry{
-					From: "$.metadata.name",
+			Assert: &gdtkube.Expect{
+				Matches: map[string]any{
+					"status": map[string]any{
+						"readyReplicas": map[string]any{
+							"$gte": 2,
+						},
+					},
 				},
 			},
 		},
 		&gdtkube.Spec{
 			Spec: api.Spec{
-				Index:    10,
-				Name:     "fetch a pod with gdt variable system substitution",
+				Plugin:   gdtkube.Plugin(),
+				Index:    36,
+				Name:     "check a deployment is ready",
 				Defaults: &api.Defaults{},
 			},
 			Kube: &gdtkube.KubeSpec{
 				Action: gdtkube.Action{
 					Get: gdtkube.NewResourceIdentifier(
-						"pods",
-						"$POD", // $$POD is replaced with $POD after envvar substitution...
-						nil,
+						"deployments", "nginx", nil,
 					),
 				},
 			},
+			Assert: &gdtkube.Expect{
+				Ready: &gdtkube.ReadyExpect{
+					Status: gdtkube.ReadyStatusCurrent,
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Plugin:   gdtkube.Plugin(),
+				Index:    37,
+				Name:     "check the number of running pods",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Get: gdtkube.NewResourceIdentifier("pods", "", nil),
+				},
+			},
+			Assert: &gdtkube.Expect{
+				LenRange: &gdtkube.LenRange{
+					Min: &lenMin,
+					Max: &lenMax,
+				},
+				Aggregates: []*gdtkube.AggregateExpect{
+					{
+						Count: "$[?@.status.phase == 'Running']",
+						Min:   "2",
+					},
+					{
+						Sum: "$[*].spec.containers[*].resources.requests.cpu",
+						Max: "1500m",
+					},
+				},
+			},
+		},
+		&gdtkube.Spec{
+			Spec: api.Spec{
+				Plugin:   gdtkube.Plugin(),
+				Index:    38,
+				Name:     "compare a deployment with a golden file",
+				Defaults: &api.Defaults{},
+			},
+			Kube: &gdtkube.KubeSpec{
+				Action: gdtkube.Action{
+					Get: gdtkube.NewResourceIdentifier("deployments", "nginx", nil),
+				},
+			},
+			Assert: &gdtkube.Expect{
+				Snapshot: &gdtkube.SnapshotExpect{
+					Path: "snapshots/nginx.yaml",
+					Ignore: []string{
+						"$.status",
+						"$.metadata.annotations",
+					},
+				},
+			},
 		},
 	}
 	require.Len(s.Tests, len(expTests))
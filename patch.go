@@ -0,0 +1,111 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/gdt-dev/core/debug"
+	"github.com/gdt-dev/core/parse"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// PatchTypeStrategic indicates a Kubernetes strategic-merge patch.
+	PatchTypeStrategic = "strategic"
+	// PatchTypeMerge indicates an RFC 7386 JSON merge patch.
+	PatchTypeMerge = "merge"
+	// PatchTypeJSON indicates an RFC 6902 JSON patch.
+	PatchTypeJSON = "json"
+)
+
+var (
+	// validPatchTypes is the set of `kube.patch.type` values we accept
+	validPatchTypes = []string{
+		PatchTypeStrategic,
+		PatchTypeMerge,
+		PatchTypeJSON,
+	}
+)
+
+// PatchAction describes a patch that will be sent to the Kubernetes API
+// server for one or more resources.
+type PatchAction struct {
+	// Target is a string or object identifying the resource(s) to patch. It
+	// takes the same forms as the argument to `kube.get`:
+	//
+	// - a string with a resource kind or kind alias, e.g. "pods", "po",
+	//   followed by a space or `/` character followed by the resource name.
+	// - an object with a `type` and optional `name` and `labels` fields
+	//   containing a label selector that should be used to select that `type`
+	//   of resource.
+	Target *ResourceIdentifier `yaml:"target"`
+	// Type is the type of patch to send. It must be one of `strategic` (the
+	// default), `merge` or `json`.
+	Type string `yaml:"type,omitempty"`
+	// Patch is a string containing either a file path or raw YAML/JSON
+	// content with the patch body. When Type is `json`, the patch body must
+	// be a list of RFC 6902 JSON patch operations.
+	Patch string `yaml:"patch"`
+}
+
+// Title returns the patch target's kind and name, if present
+func (p *PatchAction) Title() string {
+	if p.Target == nil {
+		return ""
+	}
+	return p.Target.Title()
+}
+
+// patchType returns the Kubernetes PatchType for the patch action
+func (p *PatchAction) patchType() types.PatchType {
+	switch strings.ToLower(p.Type) {
+	case PatchTypeMerge:
+		return types.MergePatchType
+	case PatchTypeJSON:
+		return types.JSONPatchType
+	}
+	return types.StrategicMergePatchType
+}
+
+// body returns the JSON-encoded patch body after reading any file and
+// replacing variables in the content.
+func (p *PatchAction) body(ctx context.Context) ([]byte, error) {
+	content := p.Patch
+	if probablyFilePath(content) {
+		b, err := os.ReadFile(content)
+		if err != nil {
+			return nil, err
+		}
+		content = parse.ExpandWithFixedDoubleDollar(string(b))
+	}
+	contentRep := gdtcontext.ReplaceVariables(ctx, content)
+	if content != contentRep {
+		debug.Printf(
+			ctx,
+			"kube.patch: replaced patch: %s -> %s",
+			content, contentRep,
+		)
+	}
+	return yaml.ToJSON([]byte(contentRep))
+}
+
+// NewPatchAction returns a new PatchAction applying the supplied patch of the
+// supplied type to the target.
+func NewPatchAction(
+	target *ResourceIdentifier,
+	patchType string,
+	patch string,
+) *PatchAction {
+	return &PatchAction{
+		Target: target,
+		Type:   patchType,
+		Patch:  patch,
+	}
+}
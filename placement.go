@@ -7,16 +7,18 @@ package kube
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/gdt-dev/core/debug"
 	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/selection"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -24,6 +26,7 @@ type node struct {
 	name        string
 	allocatable map[string]resource.Quantity
 	labels      map[string]string
+	taints      []corev1.Taint
 }
 
 // getNodes returns a slice of node objects in the Kubernetes cluster
@@ -53,45 +56,122 @@ func getNodes(
 		for k, v := range allocatable {
 			allocs[k] = resource.MustParse(v)
 		}
+		var spec corev1.NodeSpec
+		specMap, _, _ := unstructured.NestedMap(n.UnstructuredContent(), "spec")
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(specMap, &spec)
+		if err != nil {
+			panic(err)
+		}
 		nodes[x] = node{
 			name:        n.GetName(),
 			allocatable: allocs,
 			labels:      labels,
+			taints:      spec.Taints,
 		}
 	}
 	return nodes
 }
 
 type pod struct {
-	name     string
-	nodename string
+	name         string
+	namespace    string
+	nodename     string
+	phase        string
+	requests     map[string]resource.Quantity
+	tolerations  []corev1.Toleration
+	nodeSelector map[string]string
+}
+
+// podFromUnstructured returns a pod object from the supplied unstructured Pod
+func podFromUnstructured(p *unstructured.Unstructured) pod {
+	var spec corev1.PodSpec
+	specMap, _, _ := unstructured.NestedMap(p.UnstructuredContent(), "spec")
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(specMap, &spec)
+	if err != nil {
+		panic(err)
+	}
+	phase, _, _ := unstructured.NestedString(p.UnstructuredContent(), "status", "phase")
+	// A Pod's requested resources are the sum of its containers' requests.
+	requests := map[string]resource.Quantity{}
+	for _, c := range spec.Containers {
+		for name, q := range c.Resources.Requests {
+			total := requests[string(name)]
+			total.Add(q)
+			requests[string(name)] = total
+		}
+	}
+	return pod{
+		name:         p.GetName(),
+		namespace:    p.GetNamespace(),
+		nodename:     spec.NodeName,
+		phase:        phase,
+		requests:     requests,
+		tolerations:  spec.Tolerations,
+		nodeSelector: spec.NodeSelector,
+	}
 }
 
-// getPods returns a slice of pod objects in the supplied Deployment or StatefulSet
+// scheduled returns true if the pod has been bound to a node and has not
+// terminated.
+func (p pod) scheduled() bool {
+	if p.nodename == "" {
+		return false
+	}
+	return p.phase != string(corev1.PodSucceeded) &&
+		p.phase != string(corev1.PodFailed)
+}
+
+// schedulableOn returns true if the pod's node selector matches the supplied
+// node's labels and the pod tolerates the node's NoSchedule and NoExecute
+// taints.
+func (p pod) schedulableOn(n node) bool {
+	for k, v := range p.nodeSelector {
+		if n.labels[k] != v {
+			return false
+		}
+	}
+	for _, t := range n.taints {
+		if t.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := lo.ContainsBy(p.tolerations, func(tol corev1.Toleration) bool {
+			return tol.ToleratesTaint(&t)
+		})
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// workloadKinds contains the lowercased kinds of the workloads whose Pods
+// can be selected by getPods using the workload's `spec.selector`.
+var workloadKinds = []string{
+	"deployment",
+	"replicaset",
+	"statefulset",
+	"daemonset",
+	"job",
+}
+
+// getPods returns a slice of pod objects in the supplied Deployment,
+// ReplicaSet, StatefulSet, DaemonSet or Job, selected using the workload's
+// `spec.selector`.
 func getPods(
 	ctx context.Context,
 	c *connection,
 	r *unstructured.Unstructured,
-) []pod {
+) ([]pod, error) {
 	kind := strings.ToLower(r.GetKind())
+	if !lo.Contains(workloadKinds, kind) {
+		return nil, AssertionUnsupported(
+			"placement", fmt.Sprintf("kind %s", r.GetKind()),
+		)
+	}
 	ns := r.GetNamespace()
-	ls := labels.NewSelector()
-	switch kind {
-	case "deployment":
-	case "statefulset":
-		selector, _, _ := unstructured.NestedMap(r.UnstructuredContent(), "spec", "selector")
-		matchLabels, found := selector["matchLabels"]
-		if found {
-			for k, v := range matchLabels.(map[string]string) {
-				r, err := labels.NewRequirement(k, selection.Equals, []string{v})
-				if err != nil {
-					panic(err)
-				}
-				ls = ls.Add(*r)
-			}
-		}
-	default:
-		panic("unsupported placement Kind: " + kind)
+	ls, err := workloadSelector(r)
+	if err != nil {
+		return nil, err
 	}
 	gvk := schema.GroupVersionKind{
 		Kind: "Pod",
@@ -112,91 +192,437 @@ func getPods(
 	}
 	pods := make([]pod, len(list.Items))
 	for x, p := range list.Items {
-		nodename, _, _ := unstructured.NestedString(p.UnstructuredContent(), "spec", "nodeName")
-		pods[x] = pod{
-			name:     p.GetName(),
-			nodename: nodename,
-		}
+		pods[x] = podFromUnstructured(&p)
+	}
+	return pods, nil
+}
+
+// getAllPods returns a slice of pod objects for all Pods in all namespaces of
+// the Kubernetes cluster
+func getAllPods(
+	ctx context.Context,
+	c *connection,
+) []pod {
+	gvk := schema.GroupVersionKind{
+		Kind: "Pod",
+	}
+	res, err := c.gvrFromGVK(gvk)
+	if err != nil {
+		panic(err)
+	}
+	list, err := c.client.Resource(res).Namespace("").List(
+		ctx, metav1.ListOptions{},
+	)
+	if err != nil {
+		panic(err)
+	}
+	pods := make([]pod, len(list.Items))
+	for x, p := range list.Items {
+		pods[x] = podFromUnstructured(&p)
 	}
 	return pods
 }
 
-// placementSpreadOK returns true if the Pods in the subject are evenly spread
-// across hosts with the supplied topology keys
+// podsPerDomain returns a map, keyed by the value of the supplied topology
+// key (the domain), of the number of supplied pods scheduled to a node in
+// that domain. Every domain of the cluster's nodes is present in the returned
+// map, even those with no pods.
+func podsPerDomain(
+	nodes []node,
+	pods []pod,
+	topoKey string,
+) map[string]int {
+	nodeDomains := map[string]string{}
+	counts := map[string]int{}
+	for _, n := range nodes {
+		dom, found := n.labels[topoKey]
+		if !found {
+			continue
+		}
+		nodeDomains[n.name] = dom
+		counts[dom] = 0
+	}
+	for _, p := range pods {
+		if !p.scheduled() {
+			continue
+		}
+		dom, found := nodeDomains[p.nodename]
+		if found {
+			counts[dom]++
+		}
+	}
+	return counts
+}
+
+// domainCountsString returns a string with the supplied pod counts per
+// domain, sorted by domain, e.g. "zone-a: 2, zone-b: 0"
+func domainCountsString(counts map[string]int) string {
+	return strings.Join(lo.Map(sortedKeys(counts), func(dom string, _ int) string {
+		return fmt.Sprintf("%s: %d", dom, counts[dom])
+	}), ", ")
+}
+
+// placementSpreadOK returns true if the supplied Pods are evenly spread
+// across the domains of the supplied topology keys. The Pods are evenly
+// spread when the difference between the number of Pods in the domain with
+// the most Pods and the domain with the fewest Pods (the skew) is not
+// greater than the supplied maximum skew. Only domains of nodes the Pods can
+// be scheduled on are considered. As with a Pod's
+// `topologySpreadConstraints`, when there are fewer of those domains than the
+// supplied minimum number of domains, the fewest Pods in a domain is
+// considered to be zero.
 func (a *assertions) placementSpreadOK(
 	ctx context.Context,
-	res *unstructured.Unstructured,
+	nodes []node,
+	pods []pod,
 	topoKeys []string,
+	maxSkew int,
+	minDoms int,
 ) bool {
-	if len(topoKeys) == 0 {
+	if len(topoKeys) == 0 || len(pods) == 0 {
 		return true
 	}
-	nodes := getNodes(ctx, a.c)
-	domainNodes := map[string][]string{}
+	eligible := lo.Filter(nodes, func(n node, _ int) bool {
+		return pods[0].schedulableOn(n)
+	})
 	for _, k := range topoKeys {
-		domainNodes[k] = []string{}
+		counts := podsPerDomain(eligible, pods, k)
+		debug.Printf(
+			ctx, "placement-spread: topology key: %s, pods per domain: %s",
+			k, domainCountsString(counts),
+		)
+		if len(counts) == 0 {
+			continue
+		}
+		domCounts := lo.Values(counts)
+		minCount := lo.Min(domCounts)
+		if len(counts) < minDoms {
+			minCount = 0
+		}
+		skew := lo.Max(domCounts) - minCount
+		if skew > maxSkew {
+			a.Fail(PlacementNotSpread(k, skew, maxSkew, counts))
+			return false
+		}
+	}
+	return true
+}
+
+// placementPackOK returns true if the supplied Pods are packed onto hosts
+// with the supplied topology keys. The Pods are packed when they landed
+// in the fewest domains of each topology key that have enough free resources
+// for all of them.
+func (a *assertions) placementPackOK(
+	ctx context.Context,
+	nodes []node,
+	pods []pod,
+	topoKeys []string,
+) bool {
+	if len(topoKeys) == 0 || len(pods) == 0 {
+		return true
+	}
+	capacities := nodePodCapacities(nodes, pods, getAllPods(ctx, a.c))
+	for _, k := range topoKeys {
+		counts := podsPerDomain(nodes, pods, k)
+		debug.Printf(
+			ctx, "placement-pack: topology key: %s, pods per domain: %s",
+			k, domainCountsString(counts),
+		)
+		domCapacities := map[string]int{}
 		for _, n := range nodes {
-			_, found := n.labels[k]
+			dom, found := n.labels[k]
 			if found {
-				domainNodes[k] = append(domainNodes[k], n.name)
+				domCapacities[dom] += capacities[n.name]
 			}
 		}
+		expDomains := minDomains(lo.Values(domCapacities), len(pods))
+		gotDomains := len(lo.PickBy(counts, func(_ string, count int) bool {
+			return count > 0
+		}))
+		if gotDomains > expDomains {
+			a.Fail(PlacementNotPacked(k, expDomains, counts))
+			return false
+		}
 	}
+	return true
+}
 
-	// we construct a map, keyed by topology key, of maps, keyed by the value
-	// of the topology key (the domain), with counts of pods scheduled to that
-	// domain.
-	pods := getPods(ctx, a.c, res)
-	podDomains := map[string]map[string]int{}
-	for _, k := range topoKeys {
-		podDomains[k] = map[string]int{}
-		for _, dom := range domainNodes[k] {
-			podDomains[k][dom] = 0
-			for _, pod := range pods {
-				podNode := pod.nodename
-				if dom == podNode {
-					podDomains[k][dom]++
-				}
+// nodePodCapacities returns a map, keyed by node name, of the number of the
+// supplied workload pods that would fit on that node, given the node's
+// allocatable resources, the resources requested by the other pods on that
+// node and the node's taints. The workload's pods are assumed to all request
+// the same resources as the first of them.
+func nodePodCapacities(
+	nodes []node,
+	pods []pod,
+	allPods []pod,
+) map[string]int {
+	workloadPod := pods[0]
+	isWorkloadPod := map[string]bool{}
+	for _, p := range pods {
+		isWorkloadPod[p.namespace+"/"+p.name] = true
+	}
+	requests := map[string]resource.Quantity{}
+	for name, q := range workloadPod.requests {
+		requests[name] = q
+	}
+	// Each pod uses up one of the node's allocatable pods.
+	requests[string(corev1.ResourcePods)] = resource.MustParse("1")
+
+	capacities := map[string]int{}
+	for _, n := range nodes {
+		if !workloadPod.schedulableOn(n) {
+			capacities[n.name] = 0
+			continue
+		}
+		free := map[string]resource.Quantity{}
+		for name, q := range n.allocatable {
+			free[name] = q.DeepCopy()
+		}
+		for _, p := range allPods {
+			if p.nodename != n.name || !p.scheduled() ||
+				isWorkloadPod[p.namespace+"/"+p.name] {
+				continue
 			}
+			for name, q := range p.requests {
+				f := free[name]
+				f.Sub(q)
+				free[name] = f
+			}
+			f := free[string(corev1.ResourcePods)]
+			f.Sub(resource.MustParse("1"))
+			free[string(corev1.ResourcePods)] = f
 		}
+		capacity := -1
+		for name, req := range requests {
+			if req.IsZero() {
+				continue
+			}
+			f, found := free[name]
+			if !found {
+				capacity = 0
+				break
+			}
+			fits := int(f.MilliValue() / req.MilliValue())
+			if fits < 0 {
+				fits = 0
+			}
+			if capacity < 0 || fits < capacity {
+				capacity = fits
+			}
+		}
+		if capacity < 0 {
+			capacity = 0
+		}
+		capacities[n.name] = capacity
 	}
+	return capacities
+}
 
-	// Pods are evenly spread across domains defined by the topology key when
-	// the min and max number of pods on each domain is not greater than 1.
-	for domain, nodes := range domainNodes {
-		debug.Printf(
-			ctx, "placement-spread: domain: %s, unique nodes: %d",
-			domain, len(nodes),
-		)
-		if len(nodes) > 0 {
-			nodeCounts := lo.Values(podDomains[domain])
+// minDomains returns the fewest number of domains with the supplied pod
+// capacities that can hold the supplied number of pods. If all domains
+// together cannot hold the pods, the number of domains is returned.
+func minDomains(capacities []int, pods int) int {
+	sort.Sort(sort.Reverse(sort.IntSlice(capacities)))
+	total := 0
+	for x, c := range capacities {
+		total += c
+		if total >= pods {
+			return x + 1
+		}
+	}
+	return len(capacities)
+}
 
+// placementConstraintsOK returns true if the supplied Pods landed on nodes
+// that satisfy the node selector, taint, affinity, anti-affinity and
+// max-pods-per-node assertions.
+func (a *assertions) placementConstraintsOK(
+	ctx context.Context,
+	nodes []node,
+	pods []pod,
+) bool {
+	pa := a.exp.Placement
+	nodesByName := lo.KeyBy(nodes, func(n node) string {
+		return n.name
+	})
+	res := true
+	if len(pa.NodeSelector) > 0 {
+		sel := labels.SelectorFromSet(pa.NodeSelector)
+		rule := "node-selector " + sel.String()
+		for _, p := range pods {
+			n := nodesByName[p.nodename]
+			if !sel.Matches(labels.Set(n.labels)) {
+				a.Fail(PlacementViolated(p.name, p.nodename, rule))
+				res = false
+			}
+		}
+	}
+	if pa.AvoidTaints != nil {
+		for _, spec := range pa.AvoidTaints.Values() {
+			// The taint specs were validated when parsing.
+			avoid, _ := parseTaint(spec)
+			for _, p := range pods {
+				n := nodesByName[p.nodename]
+				for _, t := range n.taints {
+					if taintMatches(avoid, t) {
+						a.Fail(PlacementViolated(
+							p.name, p.nodename, "avoid-taints "+spec,
+						))
+						res = false
+					}
+				}
+			}
+		}
+	}
+	if pa.Affinity != nil {
+		res = a.placementAffinityOK(ctx, nodes, pods, pa.Affinity, true) && res
+	}
+	if pa.AntiAffinity != nil {
+		res = a.placementAffinityOK(ctx, nodes, pods, pa.AntiAffinity, false) && res
+	}
+	if pa.MaxPodsPerNode != nil {
+		maxPods := *pa.MaxPodsPerNode
+		podNames := map[string][]string{}
+		for _, p := range pods {
+			podNames[p.nodename] = append(podNames[p.nodename], p.name)
+		}
+		for _, nodename := range sortedKeys(podNames) {
+			names := podNames[nodename]
 			debug.Printf(
-				ctx, "placement-spread: domain: %s, pods per node: %d",
-				domain, nodeCounts,
+				ctx, "placement-max-pods-per-node: node: %s, pods: %d",
+				nodename, len(names),
 			)
-			minCount := lo.Min(nodeCounts)
-			maxCount := lo.Max(nodeCounts)
-			skew := maxCount - minCount
-			if skew >= 1 {
-				msg := fmt.Sprintf(
-					"found uneven spread skew of %d for domain %s",
-					skew, domain,
-				)
-				a.Fail(fmt.Errorf("%s", msg))
-				return false
+			if len(names) > maxPods {
+				a.Fail(PlacementViolated(
+					strings.Join(names, ", "), nodename,
+					fmt.Sprintf("max-pods-per-node %d", maxPods),
+				))
+				res = false
 			}
 		}
 	}
-	return true
+	return res
 }
 
-// placementPackOK returns true if the Pods in the subject are packed onto
-// hosts with the supplied topology keys
-func (a *assertions) placementPackOK(
+// placementAffinityOK returns true if each of the supplied Pods landed in a
+// domain of the affinity's topology key that has (when colocate is true) or
+// does not have (when colocate is false) one of the affinity target's Pods.
+func (a *assertions) placementAffinityOK(
 	ctx context.Context,
-	res *unstructured.Unstructured,
-	topoKeys []string,
+	nodes []node,
+	pods []pod,
+	aff *PlacementAffinity,
+	colocate bool,
 ) bool {
-	return true
+	if len(pods) == 0 {
+		return true
+	}
+	topoKey := aff.TopologyKey
+	if topoKey == "" {
+		topoKey = corev1.LabelHostname
+	}
+	rule := "anti-affinity"
+	if colocate {
+		rule = "affinity"
+	}
+	rule = fmt.Sprintf(
+		"%s with %s on %s", rule, aff.Target.Title(), topoKey,
+	)
+	targets, err := podsForTarget(ctx, a.c, pods[0].namespace, aff.Target)
+	if err != nil {
+		a.Fail(err)
+		return false
+	}
+	nodeDomains := map[string]string{}
+	for _, n := range nodes {
+		if dom, found := n.labels[topoKey]; found {
+			nodeDomains[n.name] = dom
+		}
+	}
+	// targetDomains is a map, keyed by domain, of the names of the target's
+	// Pods in that domain
+	targetDomains := map[string][]string{}
+	for _, t := range targets {
+		dom, found := nodeDomains[t.Spec.NodeName]
+		if !found {
+			continue
+		}
+		targetDomains[dom] = append(targetDomains[dom], t.Namespace+"/"+t.Name)
+	}
+	res := true
+	for _, p := range pods {
+		dom, found := nodeDomains[p.nodename]
+		// A Pod is not kept away from itself when the target selects the
+		// Pods of the subject.
+		others := lo.Without(targetDomains[dom], p.namespace+"/"+p.name)
+		colocated := found && len(others) > 0
+		if colocated == colocate {
+			continue
+		}
+		a.Fail(PlacementViolated(p.name, p.nodename, rule))
+		res = false
+	}
+	return res
+}
+
+// parseTaint returns a Taint from the supplied string in the
+// `key[=value][:effect]` format of `kubectl taint`.
+func parseTaint(spec string) (corev1.Taint, error) {
+	t := corev1.Taint{}
+	keyValue, effect, hasEffect := strings.Cut(spec, ":")
+	if hasEffect {
+		switch corev1.TaintEffect(effect) {
+		case corev1.TaintEffectNoSchedule,
+			corev1.TaintEffectPreferNoSchedule,
+			corev1.TaintEffectNoExecute:
+			t.Effect = corev1.TaintEffect(effect)
+		default:
+			return t, fmt.Errorf("unknown taint effect %q", effect)
+		}
+	}
+	t.Key, t.Value, _ = strings.Cut(keyValue, "=")
+	if t.Key == "" {
+		return t, fmt.Errorf("taint %q has no key", spec)
+	}
+	return t, nil
+}
+
+// taintMatches returns true if the supplied node taint has the key of the
+// supplied taint to avoid and, when set, its value and effect.
+func taintMatches(avoid corev1.Taint, t corev1.Taint) bool {
+	if avoid.Key != t.Key {
+		return false
+	}
+	if avoid.Value != "" && avoid.Value != t.Value {
+		return false
+	}
+	return avoid.Effect == "" || avoid.Effect == t.Effect
+}
+
+// placementPodGroups returns the groups of Pods that the placement
+// assertions are evaluated against for the supplied objects. The Pods of each
+// workload are a group, and any Pods among the objects, e.g. from a
+// `kube.get` of Pods with a label selector, together form a single group.
+func (a *assertions) placementPodGroups(
+	ctx context.Context,
+	objs []*unstructured.Unstructured,
+) ([][]pod, error) {
+	groups := [][]pod{}
+	objPods := []pod{}
+	for _, obj := range objs {
+		if strings.EqualFold(obj.GetKind(), "Pod") {
+			objPods = append(objPods, podFromUnstructured(obj))
+			continue
+		}
+		pods, err := getPods(ctx, a.c, obj)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, pods)
+	}
+	if len(objPods) > 0 {
+		groups = append(groups, objPods)
+	}
+	return groups, nil
 }
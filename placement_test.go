@@ -52,3 +52,36 @@ func TestPlacementSpread(t *testing.T) {
 	w.Flush()
 	fmt.Println(b.String())
 }
+
+func TestPlacementPack(t *testing.T) {
+	testutil.SkipIfNoKind(t)
+	require := require.New(t)
+
+	fp := filepath.Join("testdata", "placement-pack.yaml")
+	f, err := os.Open(fp)
+	require.Nil(err)
+
+	s, err := scenario.FromReader(f, scenario.WithPath(fp))
+	require.Nil(err)
+	require.NotNil(s)
+
+	kindCfgPath := "kind-config-three-workers-three-zones.yaml"
+
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+	ctx := gdtcontext.New(gdtcontext.WithDebug(w))
+
+	ctx = gdtcontext.RegisterFixture(
+		ctx, "kind-three-workers-three-zones",
+		kindfix.New(
+			kindfix.WithClusterName("kind-three-workers-three-zones"),
+			kindfix.WithConfigPath(kindCfgPath),
+		),
+	)
+
+	err = s.Run(ctx, t)
+	require.Nil(err)
+
+	w.Flush()
+	fmt.Println(b.String())
+}
@@ -0,0 +1,147 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/gdt-dev/core/debug"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// annotationDefaultContainer is the annotation kubectl uses to determine
+	// which container to exec into or fetch logs from when no container is
+	// specified.
+	annotationDefaultContainer = "kubectl.kubernetes.io/default-container"
+)
+
+// isPodResource returns true if the supplied GroupVersionResource refers to
+// core/v1 Pods.
+func isPodResource(res schema.GroupVersionResource) bool {
+	return res.Group == "" && res.Resource == "pods"
+}
+
+// workloadSelector returns the label selector contained in the supplied
+// workload resource's `spec.selector` field. Both `matchLabels` and
+// `matchExpressions` are understood.
+func workloadSelector(
+	obj *unstructured.Unstructured,
+) (labels.Selector, error) {
+	raw, found, err := unstructured.NestedMap(
+		obj.UnstructuredContent(), "spec", "selector",
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf(
+			"%s %q has no spec.selector", obj.GetKind(), obj.GetName(),
+		)
+	}
+	ls := &metav1.LabelSelector{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(raw, ls)
+	if err != nil {
+		return nil, err
+	}
+	return metav1.LabelSelectorAsSelector(ls)
+}
+
+// podsForTarget returns the Pods identified by the supplied resource
+// identifier. The identifier may refer to a single named Pod, a set of Pods
+// selected by labels, or a named workload (Deployment, StatefulSet, Job, etc)
+// whose `spec.selector` is used to select its Pods.
+func podsForTarget(
+	ctx context.Context,
+	c *connection,
+	ns string,
+	target *ResourceIdentifier,
+) ([]corev1.Pod, error) {
+	arg := gdtcontext.ReplaceVariables(ctx, target.Arg)
+	name := gdtcontext.ReplaceVariables(ctx, target.Name)
+	res, err := c.gvrFromArg(arg)
+	if err != nil {
+		return nil, err
+	}
+	pods := c.clientset.CoreV1().Pods(ns)
+	if isPodResource(res) {
+		if name != "" {
+			pod, err := pods.Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return []corev1.Pod{*pod}, nil
+		}
+		opts := metav1.ListOptions{}
+		if target.Labels != nil {
+			opts.LabelSelector = labels.Set(target.Labels).String()
+		}
+		list, err := pods.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+	if name == "" {
+		return nil, fmt.Errorf(
+			"a name is required when selecting pods from %q", arg,
+		)
+	}
+	var obj *unstructured.Unstructured
+	if c.resourceNamespaced(res) {
+		obj, err = c.client.Resource(res).Namespace(ns).Get(
+			ctx, name, metav1.GetOptions{},
+		)
+	} else {
+		obj, err = c.client.Resource(res).Get(ctx, name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	sel, err := workloadSelector(obj)
+	if err != nil {
+		return nil, err
+	}
+	debug.Printf(
+		ctx, "selecting pods for %s/%s (selector: %s)",
+		res.Resource, name, sel,
+	)
+	list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: sel.String()})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// firstRunningPod returns the first Pod in the supplied slice that is in the
+// Running phase, or nil if there is no such Pod.
+func firstRunningPod(pods []corev1.Pod) *corev1.Pod {
+	for x := range pods {
+		if pods[x].Status.Phase == corev1.PodRunning {
+			return &pods[x]
+		}
+	}
+	return nil
+}
+
+// defaultContainerName returns the name of the container to use when none
+// was specified, respecting the `kubectl.kubernetes.io/default-container`
+// annotation just like kubectl does.
+func defaultContainerName(pod *corev1.Pod) string {
+	if name, ok := pod.Annotations[annotationDefaultContainer]; ok {
+		return name
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
@@ -0,0 +1,396 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// ReadyStatusCurrent is the computed status of an object whose actual
+	// state matches its desired state, e.g. a Deployment whose replicas are
+	// all updated and available.
+	ReadyStatusCurrent = "Current"
+	// ReadyStatusInProgress is the computed status of an object whose
+	// actual state is still converging on its desired state.
+	ReadyStatusInProgress = "InProgress"
+	// ReadyStatusFailed is the computed status of an object that failed to
+	// reach its desired state, e.g. a Job with a `Failed` condition.
+	ReadyStatusFailed = "Failed"
+	// ReadyStatusTerminating is the computed status of an object that is
+	// being deleted.
+	ReadyStatusTerminating = "Terminating"
+)
+
+// readyStatuses are the valid `assert.ready` status strings.
+var readyStatuses = []string{
+	ReadyStatusCurrent,
+	ReadyStatusInProgress,
+	ReadyStatusFailed,
+	ReadyStatusTerminating,
+}
+
+// readyStatus is the computed status of an object along with a message
+// explaining it.
+type readyStatus struct {
+	status  string
+	message string
+}
+
+// readyInProgress returns a readyStatus of InProgress with the supplied
+// message.
+func readyInProgress(format string, args ...any) readyStatus {
+	return readyStatus{ReadyStatusInProgress, fmt.Sprintf(format, args...)}
+}
+
+// readyFailed returns a readyStatus of Failed with the supplied message.
+func readyFailed(format string, args ...any) readyStatus {
+	return readyStatus{ReadyStatusFailed, fmt.Sprintf(format, args...)}
+}
+
+// readyCurrent returns a readyStatus of Current with the supplied message.
+func readyCurrent(format string, args ...any) readyStatus {
+	return readyStatus{ReadyStatusCurrent, fmt.Sprintf(format, args...)}
+}
+
+// computeReadyStatus computes the status of the supplied object the same way
+// as the kstatus library used by kubectl, Flux and Config Sync: objects being
+// deleted are Terminating, objects whose `status.observedGeneration` lags
+// their `metadata.generation` are InProgress, and otherwise the status is
+// computed by rules for the built-in kind or, for other kinds, from the
+// `Ready`, `Reconciling` and `Stalled` conditions.
+func computeReadyStatus(obj *unstructured.Unstructured) readyStatus {
+	if obj.GetDeletionTimestamp() != nil {
+		return readyStatus{ReadyStatusTerminating, "deletion in progress"}
+	}
+	observed, found, err := unstructured.NestedInt64(
+		obj.Object, "status", "observedGeneration",
+	)
+	if found && err == nil && observed < obj.GetGeneration() {
+		return readyInProgress(
+			"observedGeneration %d is less than generation %d",
+			observed, obj.GetGeneration(),
+		)
+	}
+	gk := obj.GroupVersionKind().GroupKind()
+	switch gk.String() {
+	case "Deployment.apps":
+		return deploymentReadyStatus(obj)
+	case "StatefulSet.apps":
+		return statefulSetReadyStatus(obj)
+	case "ReplicaSet.apps":
+		return replicaSetReadyStatus(obj)
+	case "DaemonSet.apps":
+		return daemonSetReadyStatus(obj)
+	case "Job.batch":
+		return jobReadyStatus(obj)
+	case "Pod":
+		return podReadyStatus(obj)
+	case "PersistentVolumeClaim":
+		return pvcReadyStatus(obj)
+	case "Service":
+		return serviceReadyStatus(obj)
+	case "CustomResourceDefinition.apiextensions.k8s.io":
+		return crdReadyStatus(obj)
+	}
+	return genericReadyStatus(obj)
+}
+
+// statusInt returns the integer status field with the supplied name, or zero
+// when the field is not set.
+func statusInt(obj *unstructured.Unstructured, field string) int64 {
+	v, _, _ := unstructured.NestedInt64(obj.Object, "status", field)
+	return v
+}
+
+// specReplicas returns the object's `spec.replicas`, which defaults to 1.
+func specReplicas(obj *unstructured.Unstructured) int64 {
+	v, found, err := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found || err != nil {
+		return 1
+	}
+	return v
+}
+
+// conditionStatus returns the status and reason of the object's condition
+// with the supplied type, and false if the object has no such condition.
+func conditionStatus(
+	obj *unstructured.Unstructured,
+	condType string,
+) (string, string, bool) {
+	conds, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conds {
+		cond, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if t, _ := cond["type"].(string); t != condType {
+			continue
+		}
+		status, _ := cond["status"].(string)
+		reason, _ := cond["reason"].(string)
+		return status, reason, true
+	}
+	return "", "", false
+}
+
+// deploymentReadyStatus is Current when every replica is updated, available
+// and ready and no old replicas remain.
+func deploymentReadyStatus(obj *unstructured.Unstructured) readyStatus {
+	replicas := specReplicas(obj)
+	_, reason, _ := conditionStatus(obj, "Progressing")
+	if reason == "ProgressDeadlineExceeded" {
+		return readyFailed("progress deadline exceeded")
+	}
+	if updated := statusInt(obj, "updatedReplicas"); updated < replicas {
+		return readyInProgress("updated replicas: %d/%d", updated, replicas)
+	}
+	if total := statusInt(obj, "replicas"); total > replicas {
+		return readyInProgress("pending termination: %d", total-replicas)
+	}
+	if available := statusInt(obj, "availableReplicas"); available < replicas {
+		return readyInProgress("available replicas: %d/%d", available, replicas)
+	}
+	if ready := statusInt(obj, "readyReplicas"); ready < replicas {
+		return readyInProgress("ready replicas: %d/%d", ready, replicas)
+	}
+	if status, _, found := conditionStatus(obj, "Available"); found &&
+		status != "True" {
+		return readyInProgress("Available condition is %s", status)
+	}
+	return readyCurrent("deployment is available. replicas: %d", replicas)
+}
+
+// statefulSetReadyStatus is Current when every replica is ready and running
+// the update revision. StatefulSets with the `OnDelete` update strategy are
+// Current as soon as their replicas are ready, since their Pods are only
+// updated when deleted.
+func statefulSetReadyStatus(obj *unstructured.Unstructured) readyStatus {
+	replicas := specReplicas(obj)
+	if ready := statusInt(obj, "readyReplicas"); ready < replicas {
+		return readyInProgress("ready replicas: %d/%d", ready, replicas)
+	}
+	strategy, _, _ := unstructured.NestedString(
+		obj.Object, "spec", "updateStrategy", "type",
+	)
+	if strategy == "OnDelete" {
+		return readyCurrent("all replicas scheduled as expected")
+	}
+	partition, _, _ := unstructured.NestedInt64(
+		obj.Object, "spec", "updateStrategy", "rollingUpdate", "partition",
+	)
+	if partition > 0 {
+		expected := max(replicas-partition, 0)
+		if updated := statusInt(obj, "updatedReplicas"); updated < expected {
+			return readyInProgress(
+				"updated replicas: %d/%d", updated, expected,
+			)
+		}
+		return readyCurrent("partitioned rollout complete")
+	}
+	if cur := statusInt(obj, "currentReplicas"); cur < replicas {
+		return readyInProgress("current replicas: %d/%d", cur, replicas)
+	}
+	currentRev, _, _ := unstructured.NestedString(
+		obj.Object, "status", "currentRevision",
+	)
+	updateRev, _, _ := unstructured.NestedString(
+		obj.Object, "status", "updateRevision",
+	)
+	if currentRev != updateRev {
+		return readyInProgress("waiting for rolling update to complete")
+	}
+	return readyCurrent("all replicas ready and updated")
+}
+
+// replicaSetReadyStatus is Current when every replica is labeled, available
+// and ready.
+func replicaSetReadyStatus(obj *unstructured.Unstructured) readyStatus {
+	replicas := specReplicas(obj)
+	if status, _, _ := conditionStatus(obj, "ReplicaFailure"); status == "True" {
+		return readyInProgress("replica failure")
+	}
+	if labeled := statusInt(obj, "fullyLabeledReplicas"); labeled < replicas {
+		return readyInProgress("labeled replicas: %d/%d", labeled, replicas)
+	}
+	if available := statusInt(obj, "availableReplicas"); available < replicas {
+		return readyInProgress("available replicas: %d/%d", available, replicas)
+	}
+	if ready := statusInt(obj, "readyReplicas"); ready < replicas {
+		return readyInProgress("ready replicas: %d/%d", ready, replicas)
+	}
+	return readyCurrent("replicaset is available. replicas: %d", replicas)
+}
+
+// daemonSetReadyStatus is Current when a Pod is scheduled, updated,
+// available and ready on every node that should run one.
+func daemonSetReadyStatus(obj *unstructured.Unstructured) readyStatus {
+	desired := statusInt(obj, "desiredNumberScheduled")
+	for _, field := range []string{
+		"currentNumberScheduled",
+		"updatedNumberScheduled",
+		"numberAvailable",
+		"numberReady",
+	} {
+		if n := statusInt(obj, field); n < desired {
+			return readyInProgress("%s: %d/%d", field, n, desired)
+		}
+	}
+	return readyCurrent("all replicas scheduled as expected. replicas: %d", desired)
+}
+
+// jobReadyStatus is Current when the Job has a `Complete` condition and
+// Failed when it has a `Failed` condition.
+//
+// NOTE: kstatus considers a Job Current as soon as it has started, but test
+// authors waiting on a Job almost always want it to have completed.
+func jobReadyStatus(obj *unstructured.Unstructured) readyStatus {
+	if status, _, _ := conditionStatus(obj, "Failed"); status == "True" {
+		return readyFailed("job failed")
+	}
+	if status, _, _ := conditionStatus(obj, "Complete"); status == "True" {
+		return readyCurrent("job completed")
+	}
+	return readyInProgress(
+		"job in progress. active: %d, succeeded: %d, failed: %d",
+		statusInt(obj, "active"), statusInt(obj, "succeeded"),
+		statusInt(obj, "failed"),
+	)
+}
+
+// podReadyStatus is Current when the Pod succeeded or is running with a
+// `Ready` condition, and Failed when the Pod failed or one of its containers
+// is crash-looping or cannot pull its image.
+func podReadyStatus(obj *unstructured.Unstructured) readyStatus {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Succeeded":
+		return readyCurrent("pod has completed successfully")
+	case "Failed":
+		return readyFailed("pod has failed")
+	}
+	statuses, _, _ := unstructured.NestedSlice(
+		obj.Object, "status", "containerStatuses",
+	)
+	for _, s := range statuses {
+		cs, ok := s.(map[string]any)
+		if !ok {
+			continue
+		}
+		reason, _, _ := unstructured.NestedString(
+			cs, "state", "waiting", "reason",
+		)
+		switch reason {
+		case "CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull":
+			name, _ := cs["name"].(string)
+			return readyFailed("container %s: %s", name, reason)
+		}
+	}
+	if status, _, _ := conditionStatus(obj, "Ready"); status == "True" &&
+		phase == "Running" {
+		return readyCurrent("pod is ready")
+	}
+	return readyInProgress("pod phase: %s", phase)
+}
+
+// pvcReadyStatus is Current when the PersistentVolumeClaim is bound.
+func pvcReadyStatus(obj *unstructured.Unstructured) readyStatus {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	if phase != "Bound" {
+		return readyInProgress("PVC is not Bound. phase: %s", phase)
+	}
+	return readyCurrent("PVC is Bound")
+}
+
+// serviceReadyStatus is Current unless the Service is a LoadBalancer that
+// has not been assigned an ingress point.
+func serviceReadyStatus(obj *unstructured.Unstructured) readyStatus {
+	svcType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+	if svcType != "LoadBalancer" {
+		return readyCurrent("service is ready")
+	}
+	ingress, _, _ := unstructured.NestedSlice(
+		obj.Object, "status", "loadBalancer", "ingress",
+	)
+	if len(ingress) == 0 {
+		return readyInProgress("LoadBalancer has no ingress")
+	}
+	return readyCurrent("LoadBalancer has ingress")
+}
+
+// crdReadyStatus is Current when the CustomResourceDefinition is
+// `Established` and Failed when its names were not accepted.
+func crdReadyStatus(obj *unstructured.Unstructured) readyStatus {
+	if status, reason, _ := conditionStatus(obj, "NamesAccepted"); status == "False" {
+		return readyFailed("names not accepted: %s", reason)
+	}
+	if status, _, _ := conditionStatus(obj, "Established"); status == "True" {
+		return readyCurrent("CRD is established")
+	}
+	return readyInProgress("CRD is not established")
+}
+
+// genericReadyStatus computes the status of kinds without built-in rules,
+// e.g. custom resources, from their `Stalled`, `Reconciling` and `Ready`
+// conditions. An object with none of these conditions is Current.
+func genericReadyStatus(obj *unstructured.Unstructured) readyStatus {
+	if status, reason, _ := conditionStatus(obj, "Stalled"); status == "True" {
+		return readyFailed("Stalled condition is True: %s", reason)
+	}
+	if status, reason, _ := conditionStatus(obj, "Reconciling"); status == "True" {
+		return readyInProgress("Reconciling condition is True: %s", reason)
+	}
+	status, reason, found := conditionStatus(obj, "Ready")
+	if !found {
+		return readyCurrent("resource is current")
+	}
+	if status != "True" {
+		return readyInProgress("Ready condition is %s: %s", status, reason)
+	}
+	return readyCurrent("Ready condition is True")
+}
+
+// readyOK returns true if the computed status of every subject object is the
+// status in `assert.ready`. An empty list has no object with the status, so
+// it only passes when the status is negated.
+func (a *assertions) readyOK() bool {
+	exp := a.exp
+	if exp.Ready == nil || !a.hasSubject() {
+		return true
+	}
+	objs, ok := a.subjectObjects()
+	if !ok {
+		a.Fail(AssertionUnsupported("ready", a.subjectDescription()))
+		return false
+	}
+	if len(objs) == 0 && !exp.Ready.Not {
+		a.Fail(NotReady(
+			a.subjectDescription(), exp.Ready.String(), "no objects",
+			"the list is empty",
+		))
+		return false
+	}
+	res := true
+	for _, obj := range objs {
+		rs := computeReadyStatus(obj)
+		if (rs.status == exp.Ready.Status) == exp.Ready.Not {
+			a.Fail(NotReady(objectTitle(obj), exp.Ready.String(), rs.status, rs.message))
+			res = false
+		}
+	}
+	return res
+}
+
+// isReadyStatus returns true if the supplied string is one of the computed
+// statuses, ignoring case.
+func isReadyStatus(s string) (string, bool) {
+	return lo.Find(readyStatuses, func(status string) bool {
+		return strings.EqualFold(status, s)
+	})
+}
@@ -0,0 +1,351 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestComputeReadyStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		obj    map[string]any
+		status string
+	}{
+		{
+			name: "deployment available",
+			obj: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{"name": "nginx", "generation": int64(2)},
+				"spec":       map[string]any{"replicas": int64(2)},
+				"status": map[string]any{
+					"observedGeneration": int64(2),
+					"replicas":           int64(2),
+					"updatedReplicas":    int64(2),
+					"availableReplicas":  int64(2),
+					"readyReplicas":      int64(2),
+				},
+			},
+			status: ReadyStatusCurrent,
+		},
+		{
+			name: "deployment generation not observed",
+			obj: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{"name": "nginx", "generation": int64(3)},
+				"spec":       map[string]any{"replicas": int64(1)},
+				"status": map[string]any{
+					"observedGeneration": int64(2),
+					"replicas":           int64(1),
+					"updatedReplicas":    int64(1),
+					"availableReplicas":  int64(1),
+					"readyReplicas":      int64(1),
+				},
+			},
+			status: ReadyStatusInProgress,
+		},
+		{
+			name: "deployment not yet available",
+			obj: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{"name": "nginx"},
+				"spec":       map[string]any{"replicas": int64(2)},
+				"status": map[string]any{
+					"replicas":          int64(2),
+					"updatedReplicas":   int64(2),
+					"availableReplicas": int64(1),
+				},
+			},
+			status: ReadyStatusInProgress,
+		},
+		{
+			name: "deployment progress deadline exceeded",
+			obj: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]any{"name": "nginx"},
+				"status": map[string]any{
+					"conditions": []any{
+						map[string]any{
+							"type":   "Progressing",
+							"status": "False",
+							"reason": "ProgressDeadlineExceeded",
+						},
+					},
+				},
+			},
+			status: ReadyStatusFailed,
+		},
+		{
+			name: "statefulset rolling update in progress",
+			obj: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"metadata":   map[string]any{"name": "web"},
+				"spec":       map[string]any{"replicas": int64(3)},
+				"status": map[string]any{
+					"readyReplicas":   int64(3),
+					"currentReplicas": int64(3),
+					"currentRevision": "web-1",
+					"updateRevision":  "web-2",
+				},
+			},
+			status: ReadyStatusInProgress,
+		},
+		{
+			name: "statefulset partitioned rollout complete",
+			obj: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"metadata":   map[string]any{"name": "web"},
+				"spec": map[string]any{
+					"replicas": int64(3),
+					"updateStrategy": map[string]any{
+						"type": "RollingUpdate",
+						"rollingUpdate": map[string]any{
+							"partition": int64(2),
+						},
+					},
+				},
+				"status": map[string]any{
+					"readyReplicas":   int64(3),
+					"updatedReplicas": int64(1),
+					"currentRevision": "web-1",
+					"updateRevision":  "web-2",
+				},
+			},
+			status: ReadyStatusCurrent,
+		},
+		{
+			name: "statefulset partitioned rollout in progress",
+			obj: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"metadata":   map[string]any{"name": "web"},
+				"spec": map[string]any{
+					"replicas": int64(3),
+					"updateStrategy": map[string]any{
+						"type": "RollingUpdate",
+						"rollingUpdate": map[string]any{
+							"partition": int64(1),
+						},
+					},
+				},
+				"status": map[string]any{
+					"readyReplicas":   int64(3),
+					"updatedReplicas": int64(1),
+				},
+			},
+			status: ReadyStatusInProgress,
+		},
+		{
+			name: "statefulset on delete",
+			obj: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"metadata":   map[string]any{"name": "web"},
+				"spec": map[string]any{
+					"replicas": int64(2),
+					"updateStrategy": map[string]any{
+						"type": "OnDelete",
+					},
+				},
+				"status": map[string]any{
+					"readyReplicas":   int64(2),
+					"currentRevision": "web-1",
+					"updateRevision":  "web-2",
+				},
+			},
+			status: ReadyStatusCurrent,
+		},
+		{
+			name: "job running",
+			obj: map[string]any{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"metadata":   map[string]any{"name": "migrate-db"},
+				"status":     map[string]any{"active": int64(1)},
+			},
+			status: ReadyStatusInProgress,
+		},
+		{
+			name: "job complete",
+			obj: map[string]any{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"metadata":   map[string]any{"name": "migrate-db"},
+				"status": map[string]any{
+					"succeeded": int64(1),
+					"conditions": []any{
+						map[string]any{"type": "Complete", "status": "True"},
+					},
+				},
+			},
+			status: ReadyStatusCurrent,
+		},
+		{
+			name: "job failed",
+			obj: map[string]any{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"metadata":   map[string]any{"name": "migrate-db"},
+				"status": map[string]any{
+					"failed": int64(1),
+					"conditions": []any{
+						map[string]any{"type": "Failed", "status": "True"},
+					},
+				},
+			},
+			status: ReadyStatusFailed,
+		},
+		{
+			name: "pod running and ready",
+			obj: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata":   map[string]any{"name": "nginx"},
+				"status": map[string]any{
+					"phase": "Running",
+					"conditions": []any{
+						map[string]any{"type": "Ready", "status": "True"},
+					},
+				},
+			},
+			status: ReadyStatusCurrent,
+		},
+		{
+			name: "pod pending",
+			obj: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata":   map[string]any{"name": "nginx"},
+				"status":     map[string]any{"phase": "Pending"},
+			},
+			status: ReadyStatusInProgress,
+		},
+		{
+			name: "pod crash-looping",
+			obj: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata":   map[string]any{"name": "nginx"},
+				"status": map[string]any{
+					"phase": "Running",
+					"containerStatuses": []any{
+						map[string]any{
+							"name": "nginx",
+							"state": map[string]any{
+								"waiting": map[string]any{
+									"reason": "CrashLoopBackOff",
+								},
+							},
+						},
+					},
+				},
+			},
+			status: ReadyStatusFailed,
+		},
+		{
+			name: "pod being deleted",
+			obj: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata": map[string]any{
+					"name":              "nginx",
+					"deletionTimestamp": "2026-01-02T03:04:05Z",
+				},
+				"status": map[string]any{"phase": "Running"},
+			},
+			status: ReadyStatusTerminating,
+		},
+		{
+			name: "custom resource without conditions",
+			obj: map[string]any{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"metadata":   map[string]any{"name": "foo"},
+			},
+			status: ReadyStatusCurrent,
+		},
+		{
+			name: "custom resource not ready",
+			obj: map[string]any{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"metadata":   map[string]any{"name": "foo"},
+				"status": map[string]any{
+					"conditions": []any{
+						map[string]any{
+							"type":   "Ready",
+							"status": "False",
+							"reason": "Waiting",
+						},
+					},
+				},
+			},
+			status: ReadyStatusInProgress,
+		},
+		{
+			name: "custom resource reconciling",
+			obj: map[string]any{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"metadata":   map[string]any{"name": "foo"},
+				"status": map[string]any{
+					"conditions": []any{
+						map[string]any{"type": "Ready", "status": "True"},
+						map[string]any{"type": "Reconciling", "status": "True"},
+					},
+				},
+			},
+			status: ReadyStatusInProgress,
+		},
+		{
+			name: "custom resource stalled",
+			obj: map[string]any{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"metadata":   map[string]any{"name": "foo"},
+				"status": map[string]any{
+					"conditions": []any{
+						map[string]any{"type": "Stalled", "status": "True"},
+					},
+				},
+			},
+			status: ReadyStatusFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: tt.obj}
+			rs := computeReadyStatus(obj)
+			assert.Equal(t, tt.status, rs.status, rs.message)
+		})
+	}
+}
+
+func TestReadyOKEmptyList(t *testing.T) {
+	require := require.New(t)
+
+	empty := &unstructured.UnstructuredList{}
+
+	exp := &Expect{Ready: &ReadyExpect{Status: ReadyStatusCurrent}}
+	a := newAssertions(nil, exp, nil, empty, false)
+	require.False(a.OK(context.TODO()))
+	require.Len(a.Failures(), 1)
+	require.ErrorIs(a.Failures()[0], ErrNotReady)
+
+	exp = &Expect{Ready: &ReadyExpect{Status: ReadyStatusCurrent, Not: true}}
+	a = newAssertions(nil, exp, nil, empty, false)
+	require.True(a.OK(context.TODO()))
+}
@@ -0,0 +1,259 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdt-dev/core/debug"
+	"github.com/samber/lo"
+	"github.com/theory/jsonpath"
+	"github.com/theory/jsonpath/spec"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var (
+	// UpdateSnapshots indicates that `assert.snapshot` golden files should be
+	// rewritten with the returned objects instead of being compared with
+	// them. It may also be enabled by setting the environment variable named
+	// by UpdateSnapshotsEnvVar to a true value, e.g. `1`.
+	UpdateSnapshots = false
+)
+
+const (
+	// UpdateSnapshotsEnvVar is the environment variable that, when set to a
+	// true value, rewrites `assert.snapshot` golden files.
+	UpdateSnapshotsEnvVar = "GDT_KUBE_UPDATE_SNAPSHOTS"
+)
+
+// snapshotVolatilePaths are the JSONPath expressions of object fields that
+// change every time an object is created or updated and are always removed
+// from snapshots.
+var snapshotVolatilePaths = []string{
+	"$.metadata.uid",
+	"$.metadata.resourceVersion",
+	"$.metadata.creationTimestamp",
+	"$.metadata.managedFields",
+	"$.metadata.selfLink",
+	"$.metadata.ownerReferences[*].uid",
+}
+
+// updateSnapshots returns true if golden files should be rewritten.
+func updateSnapshots() bool {
+	if UpdateSnapshots {
+		return true
+	}
+	v, err := strconv.ParseBool(os.Getenv(UpdateSnapshotsEnvVar))
+	return err == nil && v
+}
+
+// ignoredValue replaces the values removed from a snapshot until they are
+// dropped from their parent maps and lists.
+type ignoredValue struct{}
+
+// normalizeSnapshotObject returns a copy of the supplied object without the
+// volatile fields, the timestamps in its status and the fields selected by
+// the supplied JSONPath expressions.
+func normalizeSnapshotObject(obj map[string]any, ignore []string) map[string]any {
+	normalized := runtime.DeepCopyJSON(obj)
+	paths := append([]string{}, snapshotVolatilePaths...)
+	for _, path := range append(paths, ignore...) {
+		// The JSONPath expressions were validated at parse time.
+		p, err := jsonpath.Parse(path)
+		if err != nil {
+			continue
+		}
+		for _, located := range p.SelectLocated(normalized) {
+			setAtPath(normalized, located.Path, ignoredValue{})
+		}
+	}
+	if status, ok := normalized["status"]; ok {
+		normalized["status"] = removeTimestamps(status)
+	}
+	return dropIgnored(normalized).(map[string]any)
+}
+
+// setAtPath sets the value at the supplied normalized path of the supplied
+// map or list.
+func setAtPath(subject any, path spec.NormalizedPath, value any) {
+	if len(path) == 0 {
+		return
+	}
+	for x, sel := range path {
+		last := x == len(path)-1
+		switch sel := sel.(type) {
+		case spec.Name:
+			m, ok := subject.(map[string]any)
+			if !ok {
+				return
+			}
+			if last {
+				m[string(sel)] = value
+				return
+			}
+			subject = m[string(sel)]
+		case spec.Index:
+			l, ok := subject.([]any)
+			if !ok || int(sel) >= len(l) {
+				return
+			}
+			if last {
+				l[sel] = value
+				return
+			}
+			subject = l[sel]
+		}
+	}
+}
+
+// removeTimestamps returns the supplied status value with every field whose
+// name ends with `Time`, `Timestamp` or `At` and whose value is an RFC 3339
+// timestamp marked as ignored, e.g. a Condition's `lastTransitionTime` or a
+// container's `startedAt`.
+func removeTimestamps(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, fv := range v {
+			if s, ok := fv.(string); ok && isTimestampField(k, s) {
+				v[k] = ignoredValue{}
+				continue
+			}
+			v[k] = removeTimestamps(fv)
+		}
+	case []any:
+		for x, item := range v {
+			v[x] = removeTimestamps(item)
+		}
+	}
+	return v
+}
+
+// isTimestampField returns true if the supplied field name and value look
+// like a timestamp.
+func isTimestampField(name string, value string) bool {
+	if !strings.HasSuffix(name, "Time") &&
+		!strings.HasSuffix(name, "Timestamp") &&
+		!strings.HasSuffix(name, "At") {
+		return false
+	}
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
+
+// dropIgnored returns the supplied value without the map entries and list
+// items marked as ignored.
+func dropIgnored(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, fv := range v {
+			if _, ok := fv.(ignoredValue); ok {
+				delete(v, k)
+				continue
+			}
+			v[k] = dropIgnored(fv)
+		}
+		return v
+	case []any:
+		kept := make([]any, 0, len(v))
+		for _, item := range v {
+			if _, ok := item.(ignoredValue); ok {
+				continue
+			}
+			kept = append(kept, dropIgnored(item))
+		}
+		return kept
+	}
+	return v
+}
+
+// readSnapshot returns the objects in the supplied golden file. The objects
+// are decoded the same way as the objects returned by the Kubernetes API
+// server, so that integers are int64 and not the int that a plain YAML
+// decoder would produce.
+func readSnapshot(path string) ([]map[string]any, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := unstructuredFromReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]map[string]any, len(objs))
+	for x, obj := range objs {
+		res[x] = obj.Object
+	}
+	return res, nil
+}
+
+// snapshotOK returns true if the returned objects, normalized, are the same
+// as the objects in the `assert.snapshot` golden file. When snapshots are
+// being updated, the golden file is rewritten instead.
+func (a *assertions) snapshotOK(ctx context.Context) bool {
+	exp := a.exp
+	if exp.Snapshot == nil || !a.hasSubject() {
+		return true
+	}
+	objs, ok := a.subjectObjects()
+	if !ok {
+		a.Fail(AssertionUnsupported("snapshot", a.subjectDescription()))
+		return false
+	}
+	actual := make([]map[string]any, len(objs))
+	for x, obj := range objs {
+		actual[x] = normalizeSnapshotObject(obj.Object, exp.Snapshot.Ignore)
+	}
+	actualYAML, err := marshalYAML(lo.ToAnySlice(actual)...)
+	if err != nil {
+		a.Fail(err)
+		return false
+	}
+	path := exp.Snapshot.Path
+	if updateSnapshots() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			a.Fail(err)
+			return false
+		}
+		if err := os.WriteFile(path, []byte(actualYAML), 0o644); err != nil {
+			a.Fail(err)
+			return false
+		}
+		debug.Printf(ctx, "kube.assert.snapshot: updated %s", path)
+		return true
+	}
+	golden, err := readSnapshot(path)
+	if errors.Is(err, os.ErrNotExist) {
+		a.Fail(SnapshotNotFound(path))
+		return false
+	}
+	if err != nil {
+		a.Fail(err)
+		return false
+	}
+	// The golden file is normalized too, so that hand-edited golden files
+	// with different formatting or volatile fields still match.
+	for x, obj := range golden {
+		golden[x] = normalizeSnapshotObject(obj, exp.Snapshot.Ignore)
+	}
+	goldenYAML, err := marshalYAML(lo.ToAnySlice(golden)...)
+	if err != nil {
+		a.Fail(err)
+		return false
+	}
+	if goldenYAML == actualYAML {
+		return true
+	}
+	diff := renderYAMLDiff(path, "actual", goldenYAML, actualYAML)
+	debug.Printf(ctx, "kube.assert.snapshot: %s differs:\n%s", path, diff)
+	a.Fail(SnapshotMismatch(path, diff))
+	return false
+}
@@ -0,0 +1,114 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSnapshot = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  uid: 0b4f3f9c-6c3d-4a4e-9f1e-1c0e7e6b2a51
+  resourceVersion: "1234"
+  creationTimestamp: "2026-01-02T03:04:05Z"
+  annotations:
+    deployment.kubernetes.io/revision: "1"
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: nginx
+          image: nginx:1.7.9
+          ports:
+            - containerPort: 80
+status:
+  replicas: 2
+  conditions:
+    - type: Available
+      status: "True"
+      lastTransitionTime: "2026-01-02T03:04:05Z"
+      lastUpdateTime: "2026-01-02T03:04:05Z"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: static-config
+data:
+  ratio: "0.5"
+`
+
+func TestReadSnapshotNormalize(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "snapshot.yaml")
+	require.Nil(os.WriteFile(path, []byte(testSnapshot), 0o644))
+
+	golden, err := readSnapshot(path)
+	require.Nil(err)
+	require.Len(golden, 2)
+
+	var normalized map[string]any
+	require.NotPanics(func() {
+		normalized = normalizeSnapshotObject(
+			golden[0], []string{"$.metadata.annotations"},
+		)
+	})
+	assert.Equal(map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name": "nginx",
+		},
+		"spec": map[string]any{
+			"replicas": int64(2),
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{
+							"name":  "nginx",
+							"image": "nginx:1.7.9",
+							"ports": []any{
+								map[string]any{"containerPort": int64(80)},
+							},
+						},
+					},
+				},
+			},
+		},
+		"status": map[string]any{
+			"replicas": int64(2),
+			"conditions": []any{
+				map[string]any{
+					"type":   "Available",
+					"status": "True",
+				},
+			},
+		},
+	}, normalized)
+
+	// The golden object itself is left untouched.
+	assert.Contains(golden[0]["metadata"], "uid")
+
+	normalized = normalizeSnapshotObject(golden[1], nil)
+	assert.Equal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name": "static-config",
+		},
+		"data": map[string]any{
+			"ratio": "0.5",
+		},
+	}, normalized)
+}
@@ -11,6 +11,16 @@ import (
 	"github.com/gdt-dev/core/api"
 )
 
+const (
+	// DryRunServer is the `dry-run` value indicating that mutating requests
+	// are validated and admitted by the Kubernetes API server but not
+	// persisted.
+	DryRunServer = "server"
+	// DryRunNone is the `dry-run` value indicating that mutating requests are
+	// persisted as normal.
+	DryRunNone = "none"
+)
+
 // KubeSpec is the complex type containing all of the Kubernetes-specific
 // actions. Most users will use the `kube.create`, `kube.apply` and
 // `kube.describe` shortcut fields.
@@ -32,6 +42,28 @@ type KubeSpec struct {
 	// calling the Kubernetes API. If empty, any namespace specified in the
 	// Defaults is used and then the string "default" is used.
 	Namespace string `yaml:"namespace,omitempty"`
+	// DryRun is a string indicating whether the create, apply, patch and
+	// delete requests for this Spec should be sent in dry-run mode. It must be
+	// either `server`, in which case the Kubernetes API server fully
+	// validates and admits the request but does not persist the result, or
+	// `none`. If empty, the `kube` defaults' `dry-run` value will be used.
+	DryRun string `yaml:"dry-run,omitempty"`
+	// FieldManager is the name of the field manager sent with the create,
+	// apply and patch requests for this Spec. If empty, "gdt-kube" is used
+	// for apply and patch requests and the Kubernetes API server chooses the
+	// field manager for create requests.
+	FieldManager string `yaml:"field-manager,omitempty"`
+	// Force indicates whether server-side apply requests for this Spec should
+	// force the field manager to take ownership of fields owned by other
+	// field managers. If nil, server-side apply requests are forced. Set to
+	// false in order to assert on field manager conflicts with
+	// `assert.conflict`.
+	Force *bool `yaml:"force,omitempty"`
+	// FailOnDeprecation indicates whether the test should fail when the
+	// Kubernetes API server returns a deprecation warning, e.g. for a
+	// manifest using a deprecated apiVersion. If nil, the `kube` defaults'
+	// `fail-on-deprecation` value will be used.
+	FailOnDeprecation *bool `yaml:"fail-on-deprecation,omitempty"`
 }
 
 // Spec describes a test of a *single* Kubernetes API request and response.
@@ -75,6 +107,34 @@ type Spec struct {
 	//     having such a label.
 	//   * the string `--all` to delete all resources of that kind.
 	KubeDelete string `yaml:"kube.delete,omitempty"`
+	// KubePatch is a shortcut for the `KubeSpec.Patch`. It is an object
+	// containing a `target` resource identifier, an optional patch `type` and
+	// the `patch` body to send to the Kubernetes API server.
+	KubePatch *PatchAction `yaml:"kube.patch,omitempty"`
+	// KubeExec is a shortcut for the `KubeSpec.Exec`. It is an object
+	// containing a `target` Pod or workload, an optional `container` and the
+	// `command` to execute in that container.
+	KubeExec *ExecAction `yaml:"kube.exec,omitempty"`
+	// KubeLogs is a shortcut for the `KubeSpec.Logs`. It is an object
+	// containing a `target` Pod or workload and options controlling which
+	// container logs to fetch.
+	KubeLogs *LogsAction `yaml:"kube.logs,omitempty"`
+	// KubeDescribe is a shortcut for the `KubeSpec.Describe`. It is a string
+	// or object identifying a single resource to describe along with its
+	// Events, owned children and, for workloads, Pod status.
+	KubeDescribe *ResourceIdentifier `yaml:"kube.describe,omitempty"`
+	// KubeWait is a shortcut for the `KubeSpec.Wait`. It is an object
+	// containing a `target` resource identifier and a `for` field describing
+	// the state to wait for, using `kubectl wait --for` syntax.
+	KubeWait *WaitAction `yaml:"kube.wait,omitempty"`
+	// KubeWatch is a shortcut for the `KubeSpec.Watch`. It is an object
+	// containing a `target` resource identifier and an optional `duration`
+	// and `until` predicate controlling how long to record changes for.
+	KubeWatch *WatchAction `yaml:"kube.watch,omitempty"`
+	// KubeEvents is a shortcut for the `KubeSpec.Events`. It is a string or
+	// object identifying the object(s) whose Events should be fetched, or an
+	// object containing a `target` and optional `since` duration.
+	KubeEvents *EventsAction `yaml:"kube.events,omitempty"`
 	// Require is an object containing the conditions that the Spec will
 	// assert. If any condition fails, the test scenario execution will stop
 	// and be marked as failed.
@@ -95,11 +155,13 @@ func (s *Spec) Retry() *api.Retry {
 		// The user may have overridden in the test spec file...
 		return s.Spec.Retry
 	}
-	if s.Kube.Action.Get != nil {
+	a := s.Kube.Action
+	if a.Get != nil || a.Logs != nil || a.Describe != nil || a.Events != nil {
 		// returning nil here means the plugin's default will be used...
 		return nil
 	}
-	// for apply/create/delete, we don't want to retry...
+	// for apply/create/delete/patch/exec, we don't want to retry. wait and
+	// watch already run until the spec's timeout themselves...
 	return api.NoRetry
 }
 
@@ -137,6 +199,27 @@ func (s *Spec) Title() string {
 	if s.Kube.Delete != nil {
 		return "kube.delete:" + s.Kube.Delete.Title()
 	}
+	if s.Kube.Patch != nil {
+		return "kube.patch:" + s.Kube.Patch.Title()
+	}
+	if s.Kube.Exec != nil {
+		return "kube.exec:" + s.Kube.Exec.Title()
+	}
+	if s.Kube.Logs != nil {
+		return "kube.logs:" + s.Kube.Logs.Title()
+	}
+	if s.Kube.Describe != nil {
+		return "kube.describe:" + s.Kube.Describe.Title()
+	}
+	if s.Kube.Wait != nil {
+		return "kube.wait:" + s.Kube.Wait.Title()
+	}
+	if s.Kube.Watch != nil {
+		return "kube.watch:" + s.Kube.Watch.Title()
+	}
+	if s.Kube.Events != nil {
+		return "kube.events:" + s.Kube.Events.Title()
+	}
 	return ""
 }
 
@@ -174,3 +257,39 @@ func (s *Spec) Namespace() string {
 	}
 	return "default"
 }
+
+// DryRun returns the dry-run mode to use for create, apply, patch and delete
+// requests. We evaluate which mode to use by looking at the following things,
+// in this order:
+//
+// 1) The Spec.Kube.DryRun value
+// 2) The Defaults.DryRun value
+// 3) Use the string "none"
+func (s *Spec) DryRun() string {
+	if s.Kube.DryRun != "" {
+		return s.Kube.DryRun
+	}
+	d := fromBaseDefaults(s.Defaults)
+	if d != nil && d.DryRun != "" {
+		return d.DryRun
+	}
+	return DryRunNone
+}
+
+// FailOnDeprecation returns whether a deprecation warning returned by the
+// Kubernetes API server should fail the test. We evaluate this by looking at
+// the following things, in this order:
+//
+// 1) The Spec.Kube.FailOnDeprecation value
+// 2) The Defaults.FailOnDeprecation value
+// 3) Use false
+func (s *Spec) FailOnDeprecation() bool {
+	if s.Kube.FailOnDeprecation != nil {
+		return *s.Kube.FailOnDeprecation
+	}
+	d := fromBaseDefaults(s.Defaults)
+	if d != nil {
+		return d.FailOnDeprecation
+	}
+	return false
+}
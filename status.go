@@ -0,0 +1,108 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// statusOK returns true if the supplied status error matches the Status
+// conditions, false otherwise.
+func (a *assertions) statusOK(apierr *apierrors.StatusError) bool {
+	exp := a.exp.Status
+	status := apierr.ErrStatus
+	ok := true
+	if exp.Code != 0 && int(status.Code) != exp.Code {
+		a.Fail(StatusDoesNotMatch(fmt.Sprintf(
+			"expected code %d but got %d: %s", exp.Code, status.Code, apierr,
+		)))
+		ok = false
+	}
+	if exp.Reason != "" && !strings.EqualFold(string(status.Reason), exp.Reason) {
+		a.Fail(StatusDoesNotMatch(fmt.Sprintf(
+			"expected reason %q but got %q: %s", exp.Reason, status.Reason, apierr,
+		)))
+		ok = false
+	}
+	if exp.Message != "" && !strings.Contains(status.Message, exp.Message) {
+		a.Fail(StatusDoesNotMatch(fmt.Sprintf(
+			"expected message to contain %q but got %q",
+			exp.Message, status.Message,
+		)))
+		ok = false
+	}
+	var causes []metav1.StatusCause
+	if status.Details != nil {
+		causes = status.Details.Causes
+	}
+	for _, expCause := range exp.Causes {
+		if !statusCauseFound(expCause, causes) {
+			a.Fail(StatusDoesNotMatch(fmt.Sprintf(
+				"expected cause %s but got %s",
+				expCause, statusCausesString(causes),
+			)))
+			ok = false
+		}
+	}
+	return ok
+}
+
+// statusCauseFound returns true if any of the supplied causes matches the
+// expected cause.
+func statusCauseFound(
+	exp *StatusCauseExpect,
+	causes []metav1.StatusCause,
+) bool {
+	for _, cause := range causes {
+		if exp.Field != "" && strings.TrimPrefix(cause.Field, ".") !=
+			strings.TrimPrefix(exp.Field, ".") {
+			continue
+		}
+		if exp.Type != "" && string(cause.Type) != exp.Type {
+			continue
+		}
+		if exp.Message != "" && !strings.Contains(cause.Message, exp.Message) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// String returns a description of the expected cause for use in failure
+// messages.
+func (e *StatusCauseExpect) String() string {
+	parts := []string{}
+	if e.Field != "" {
+		parts = append(parts, "field="+e.Field)
+	}
+	if e.Type != "" {
+		parts = append(parts, "type="+e.Type)
+	}
+	if e.Message != "" {
+		parts = append(parts, fmt.Sprintf("message contains %q", e.Message))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// statusCausesString returns a description of the supplied causes for use in
+// failure messages.
+func statusCausesString(causes []metav1.StatusCause) string {
+	if len(causes) == 0 {
+		return "no causes"
+	}
+	parts := make([]string, len(causes))
+	for x, cause := range causes {
+		parts[x] = fmt.Sprintf(
+			"{field=%s, type=%s, message=%q}",
+			cause.Field, cause.Type, cause.Message,
+		)
+	}
+	return strings.Join(parts, ", ")
+}
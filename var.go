@@ -20,6 +20,21 @@ type VarEntry struct {
 	// sourced from. This string is a JSONPath expression that contains
 	// instructions on how to extract a particular field from a Kubernetes
 	// resource fetched in the `kube.get` command.
+	//
+	// For `kube.create` and `kube.apply`, the JSONPath expression is
+	// evaluated against the array of created or applied objects, e.g.
+	// `$[0].metadata.uid`. For `kube.exec`, it is evaluated against an object
+	// with `stdout`, `stderr`, `exitCode`, `pod` and `container` fields. For
+	// `kube.logs`, it is evaluated against an object with a `logs` field
+	// containing the fetched logs and a `sources` field containing the
+	// "pod/container" names the logs were fetched from. For `kube.describe`,
+	// it is evaluated against the composite document with `object`, `events`,
+	// `children`, `pods` and `podStatus` fields. For `kube.wait`, it is
+	// evaluated against the list of waited-on objects as last observed. For
+	// `kube.watch`, it is evaluated against the list of recorded events, each
+	// with a `type` and an `object` field. For `kube.events`, it is evaluated
+	// against the list of fetched Events, each with `type`, `reason`,
+	// `message`, `count`, `timestamp` and `involvedObject` fields.
 	From string `yaml:"from"`
 }
 
@@ -59,6 +74,20 @@ func extractFrom(path string, out any) (any, error) {
 			results[x] = item.Object
 		}
 		normalized = results
+	case []*unstructured.Unstructured:
+		normalized = objectPtrsAsSlice(out)
+	case *execOutput:
+		normalized = out.asMap()
+	case *logsOutput:
+		normalized = out.asMap()
+	case *describeOutput:
+		normalized = out.asMap()
+	case *waitOutput:
+		normalized = out.items()
+	case *watchOutput:
+		normalized = out.items()
+	case *eventsOutput:
+		normalized = out.items()
 	case map[string]any:
 		normalized = out
 	case []map[string]any:
@@ -0,0 +1,499 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/gdt-dev/core/debug"
+	"github.com/theory/jsonpath"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+const (
+	// WaitForCondition is the `kube.wait.for` prefix indicating the wait is
+	// for a status condition, e.g. `condition=Ready` or
+	// `condition=Ready=False`.
+	WaitForCondition = "condition"
+	// WaitForDelete is the `kube.wait.for` value indicating the wait is for
+	// the object(s) to be deleted.
+	WaitForDelete = "delete"
+	// WaitForJSONPath is the `kube.wait.for` prefix indicating the wait is for
+	// a JSONPath expression to have a value, e.g.
+	// `jsonpath={.status.phase}=Running`.
+	WaitForJSONPath = "jsonpath"
+)
+
+const (
+	// waitReportMargin is the maximum amount of time before the test spec's
+	// deadline that a `kube.wait` stops watching so that it can report the
+	// last observed state of the objects that did not converge.
+	waitReportMargin = 500 * time.Millisecond
+)
+
+// WaitAction describes a wait for one or more resources to reach a desired
+// state, similar to `kubectl wait`.
+type WaitAction struct {
+	// Target is a string or object identifying the resource(s) to wait for.
+	// It takes the same forms as the argument to `kube.get`:
+	//
+	// - a string with a resource kind or kind alias, e.g. "pods", "po",
+	//   followed by a space or `/` character followed by the resource name.
+	// - an object with a `type` and optional `name` and `labels` fields
+	//   containing a label selector that should be used to select that `type`
+	//   of resource.
+	Target *ResourceIdentifier `yaml:"target"`
+	// For is the state to wait for, using the same syntax as `kubectl wait
+	// --for`. It must be one of the following:
+	//
+	// - `condition=<type>` or `condition=<type>=<status>` to wait for a
+	//   status condition. The status defaults to `True`. Both the condition
+	//   type and status are matched case-insensitively.
+	// - `delete` to wait for the resource(s) to be deleted.
+	// - `jsonpath=<expression>` or `jsonpath=<expression>=<value>` to wait for
+	//   a JSONPath expression to be present or to have a value. The
+	//   expression may use either the kubectl `{.status.phase}` form or the
+	//   `$.status.phase` form.
+	For string `yaml:"for"`
+}
+
+// Title returns the wait target's kind and name, if present
+func (w *WaitAction) Title() string {
+	if w.Target == nil {
+		return ""
+	}
+	return w.Target.Title()
+}
+
+// NewWaitAction returns a new WaitAction waiting on the target for the
+// supplied condition.
+func NewWaitAction(
+	target *ResourceIdentifier,
+	waitFor string,
+) *WaitAction {
+	return &WaitAction{
+		Target: target,
+		For:    waitFor,
+	}
+}
+
+// waitFor is the parsed representation of the `kube.wait.for` field
+type waitFor struct {
+	// kind is one of WaitForCondition, WaitForDelete or WaitForJSONPath
+	kind string
+	// condType is the condition type to wait for
+	condType string
+	// condStatus is the condition status to wait for
+	condStatus string
+	// path is the JSONPath expression to evaluate
+	path *jsonpath.Path
+	// pathStr is the JSONPath expression string to evaluate
+	pathStr string
+	// value is the value the JSONPath expression should have. If nil, the
+	// expression only needs to select something.
+	value *string
+}
+
+// parseWaitFor parses the supplied `kube.wait.for` string
+func parseWaitFor(subject string) (*waitFor, error) {
+	kind, rest, _ := strings.Cut(subject, "=")
+	switch strings.ToLower(kind) {
+	case WaitForDelete:
+		if rest != "" {
+			return nil, fmt.Errorf("%q does not accept a value", WaitForDelete)
+		}
+		return &waitFor{kind: WaitForDelete}, nil
+	case WaitForCondition:
+		condType, condStatus, found := strings.Cut(rest, "=")
+		if condType == "" {
+			return nil, fmt.Errorf("missing condition type")
+		}
+		if !found {
+			condStatus = "True"
+		}
+		return &waitFor{
+			kind:       WaitForCondition,
+			condType:   condType,
+			condStatus: condStatus,
+		}, nil
+	case WaitForJSONPath:
+		expr, value, found := cutJSONPathExpression(rest)
+		if expr == "" {
+			return nil, fmt.Errorf("missing JSONPath expression")
+		}
+		p, err := jsonpath.Parse(expr)
+		if err != nil {
+			return nil, err
+		}
+		wf := &waitFor{kind: WaitForJSONPath, path: p, pathStr: expr}
+		if found {
+			wf.value = &value
+		}
+		return wf, nil
+	}
+	return nil, fmt.Errorf(
+		"expected one of %q, %q or %q",
+		WaitForCondition+"=", WaitForDelete, WaitForJSONPath+"=",
+	)
+}
+
+// cutJSONPathExpression splits the supplied `<expression>=<value>` string
+// into the expression and value, converting any kubectl-style
+// `{.status.phase}` expression into a `$.status.phase` expression. Quotes
+// surrounding the expression are removed.
+func cutJSONPathExpression(subject string) (string, string, bool) {
+	subject = strings.Trim(subject, "'\"")
+	var expr, value string
+	var found bool
+	if strings.HasPrefix(subject, "{") {
+		end := strings.Index(subject, "}")
+		if end < 0 {
+			return subject, "", false
+		}
+		expr = "$" + subject[1:end]
+		rest := strings.TrimLeft(subject[end+1:], "'\"")
+		value, found = strings.CutPrefix(rest, "=")
+	} else {
+		expr, value, found = strings.Cut(subject, "=")
+	}
+	return expr, strings.Trim(value, "'\""), found
+}
+
+// check returns whether the supplied object has reached the desired state
+// along with a description of the object's observed state.
+func (wf *waitFor) check(obj *unstructured.Unstructured) (bool, string) {
+	switch wf.kind {
+	case WaitForDelete:
+		return false, fmt.Sprintf(
+			"still exists (resourceVersion: %s)", obj.GetResourceVersion(),
+		)
+	case WaitForCondition:
+		conds, _, _ := unstructured.NestedSlice(
+			obj.Object, "status", "conditions",
+		)
+		for _, c := range conds {
+			cond, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			condType, _ := cond["type"].(string)
+			if !strings.EqualFold(condType, wf.condType) {
+				continue
+			}
+			status, _ := cond["status"].(string)
+			reason, _ := cond["reason"].(string)
+			message, _ := cond["message"].(string)
+			observed := fmt.Sprintf(
+				"condition %s=%s (reason: %q, message: %q)",
+				condType, status, reason, message,
+			)
+			return strings.EqualFold(status, wf.condStatus), observed
+		}
+		return false, fmt.Sprintf("condition %s not present", wf.condType)
+	case WaitForJSONPath:
+		nodes := wf.path.Select(obj.Object)
+		if len(nodes) == 0 {
+			return false, fmt.Sprintf("%s not present", wf.pathStr)
+		}
+		got := nodes[0]
+		gotStr, ok := got.(string)
+		if !ok {
+			b, _ := json.Marshal(got)
+			gotStr = string(b)
+		}
+		observed := fmt.Sprintf("%s=%s", wf.pathStr, gotStr)
+		if wf.value == nil {
+			return true, observed
+		}
+		return gotStr == *wf.value, observed
+	}
+	return false, "unknown wait"
+}
+
+// waitState tracks the last observed state of each object being waited on
+type waitState struct {
+	wf *waitFor
+	// name is the name of the single object being waited on, if any
+	name string
+	// objects contains the last observed version of each object, keyed by
+	// name
+	objects map[string]*unstructured.Unstructured
+}
+
+// observe records the supplied object's latest state
+func (s *waitState) observe(obj *unstructured.Unstructured) {
+	s.objects[obj.GetName()] = obj
+}
+
+// forget records the deletion of the supplied object
+func (s *waitState) forget(obj *unstructured.Unstructured) {
+	delete(s.objects, obj.GetName())
+}
+
+// pending returns a map, keyed by object name, of the last observed state of
+// each object that has not reached the desired state.
+func (s *waitState) pending() map[string]string {
+	res := map[string]string{}
+	if s.wf.kind != WaitForDelete && len(s.objects) == 0 {
+		// Conditions and JSONPath expressions can only be satisfied by
+		// objects that exist.
+		name := s.name
+		if name == "" {
+			name = "*"
+		}
+		res[name] = "not found"
+		return res
+	}
+	for name, obj := range s.objects {
+		ok, observed := s.wf.check(obj)
+		if !ok {
+			res[name] = observed
+		}
+	}
+	return res
+}
+
+// waitOutput contains the results of a `kube.wait` action
+type waitOutput struct {
+	// resource is the resource kind that was waited on
+	resource string
+	// objects contains the last observed version of the objects that were
+	// waited on, sorted by name
+	objects []*unstructured.Unstructured
+	// pending is a map, keyed by object name, of the last observed state of
+	// the objects that did not reach the desired state before the timeout
+	pending map[string]string
+}
+
+// items returns the last observed objects as a slice of any suitable for
+// JSONPath lookups.
+func (o *waitOutput) items() []any {
+	res := make([]any, len(o.objects))
+	for x, obj := range o.objects {
+		res[x] = obj.Object
+	}
+	return res
+}
+
+// resourceInterface returns the dynamic client interface for the supplied
+// resource, scoped to the supplied namespace for namespaced resources.
+func resourceInterface(
+	c *connection,
+	res schema.GroupVersionResource,
+	ns string,
+) dynamic.ResourceInterface {
+	if c.resourceNamespaced(res) {
+		return c.client.Resource(res).Namespace(ns)
+	}
+	return c.client.Resource(res)
+}
+
+// targetListOptions returns the ListOptions selecting the resource(s)
+// identified by the supplied name and labels.
+func targetListOptions(
+	name string,
+	withlabels map[string]string,
+) metav1.ListOptions {
+	opts := metav1.ListOptions{}
+	if name != "" {
+		opts.FieldSelector = fields.OneTermEqualSelector(
+			"metadata.name", name,
+		).String()
+	}
+	if withlabels != nil {
+		// We already validated the label selector during parse-time
+		opts.LabelSelector = labels.Set(withlabels).String()
+	}
+	return opts
+}
+
+// newRetryWatcher returns a watcher for the supplied resource that starts at
+// the supplied resourceVersion and transparently re-establishes the watch if
+// the API server closes it.
+func newRetryWatcher(
+	ctx context.Context,
+	ri dynamic.ResourceInterface,
+	resourceVersion string,
+	opts metav1.ListOptions,
+) (*watchtools.RetryWatcher, error) {
+	lw := &cache.ListWatch{
+		WatchFuncWithContext: func(
+			ctx context.Context,
+			o metav1.ListOptions,
+		) (watch.Interface, error) {
+			o.FieldSelector = opts.FieldSelector
+			o.LabelSelector = opts.LabelSelector
+			return ri.Watch(ctx, o)
+		},
+	}
+	return watchtools.NewRetryWatcherWithContext(ctx, resourceVersion, lw)
+}
+
+// withReportMargin returns a context whose deadline is slightly before the
+// supplied context's deadline, giving the caller time to report results
+// before the test spec times out.
+func withReportMargin(
+	ctx context.Context,
+) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(
+		ctx, deadline.Add(-reportMargin(time.Until(deadline))),
+	)
+}
+
+// reportMargin returns how long before the end of the supplied remaining time
+// a `kube.wait` or `kube.watch` stops watching: a tenth of the remaining time,
+// up to waitReportMargin.
+func reportMargin(remaining time.Duration) time.Duration {
+	return min(remaining/10, waitReportMargin)
+}
+
+// wait watches the targeted resource(s) until they reach the desired state or
+// the test spec's timeout is about to be exceeded, and populates `out` with a
+// `*waitOutput`.
+func (a *Action) wait(
+	ctx context.Context,
+	c *connection,
+	ns string,
+	out *interface{},
+) error {
+	target := a.Wait.Target
+	arg := target.Arg
+	argRep := gdtcontext.ReplaceVariables(ctx, arg)
+	if arg != argRep {
+		debug.Printf(
+			ctx,
+			"kube.wait: replaced arg: %s -> %s",
+			arg, argRep,
+		)
+	}
+	name := target.Name
+	nameRep := gdtcontext.ReplaceVariables(ctx, name)
+	if name != nameRep {
+		debug.Printf(
+			ctx,
+			"kube.wait: replaced name: %s -> %s",
+			name, nameRep,
+		)
+	}
+	res, err := c.gvrFromArg(argRep)
+	if err != nil {
+		return err
+	}
+	// We validated the `for` field during parse
+	wf, _ := parseWaitFor(a.Wait.For)
+	st := &waitState{
+		wf:      wf,
+		name:    nameRep,
+		objects: map[string]*unstructured.Unstructured{},
+	}
+	ri := resourceInterface(c, res, ns)
+	opts := targetListOptions(nameRep, target.Labels)
+	debug.Printf(
+		ctx, "kube.wait: %s for %s (ns: %s, fields: %q, labels: %q)",
+		res.Resource, a.Wait.For, ns, opts.FieldSelector, opts.LabelSelector,
+	)
+	list, err := ri.List(ctx, opts)
+	if err != nil {
+		return err
+	}
+	for x := range list.Items {
+		st.observe(&list.Items[x])
+	}
+
+	waitCtx, cancel := withReportMargin(ctx)
+	defer cancel()
+	if len(st.pending()) > 0 {
+		w, err := newRetryWatcher(waitCtx, ri, list.GetResourceVersion(), opts)
+		if err != nil {
+			return err
+		}
+		defer w.Stop()
+	watchLoop:
+		for {
+			select {
+			case <-waitCtx.Done():
+				break watchLoop
+			case ev, ok := <-w.ResultChan():
+				if !ok {
+					break watchLoop
+				}
+				switch ev.Type {
+				case watch.Added, watch.Modified:
+					if obj, ok := ev.Object.(*unstructured.Unstructured); ok {
+						st.observe(obj)
+					}
+				case watch.Deleted:
+					if obj, ok := ev.Object.(*unstructured.Unstructured); ok {
+						st.forget(obj)
+					}
+				case watch.Error:
+					return apierrors.FromObject(ev.Object)
+				}
+				debug.Printf(
+					ctx, "kube.wait: %s event for %s",
+					ev.Type, res.Resource,
+				)
+				if len(st.pending()) == 0 {
+					break watchLoop
+				}
+			}
+		}
+	}
+	wo := &waitOutput{
+		resource: res.Resource,
+		pending:  st.pending(),
+	}
+	for _, obj := range st.objects {
+		wo.objects = append(wo.objects, obj)
+	}
+	sort.Slice(wo.objects, func(i, j int) bool {
+		return wo.objects[i].GetName() < wo.objects[j].GetName()
+	})
+	if len(wo.pending) == 0 {
+		debug.Printf(ctx, "kube.wait: %s converged", res.Resource)
+	}
+	*out = wo
+	return nil
+}
+
+// waitOK returns true if the subject is the output of a `kube.wait` action
+// and all waited-on objects reached the desired state, false otherwise.
+func (a *assertions) waitOK() bool {
+	out, ok := a.r.(*waitOutput)
+	if !ok || out == nil {
+		return true
+	}
+	if len(out.pending) == 0 {
+		return true
+	}
+	names := make([]string, 0, len(out.pending))
+	for name := range out.pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a.Fail(WaitNotConverged(out.resource, name, out.pending[name]))
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/gdt-dev/core/debug"
+	"github.com/samber/lo"
+	"k8s.io/client-go/rest"
+)
+
+// warningCodeMiscellaneous is the warn-code that the Kubernetes API server
+// uses for all the warnings it returns in `Warning` response headers.
+const warningCodeMiscellaneous = 299
+
+// warningRecorder is a client-go rest.WarningHandlerWithContext that records
+// the `Warning` headers returned by the Kubernetes API server, for instance
+// for requests using a deprecated apiVersion, from Pod Security Admission in
+// `warn` mode or from a ValidatingAdmissionPolicy with a `Warn` action.
+type warningRecorder struct {
+	sync.Mutex
+	texts []string
+}
+
+var _ rest.WarningHandlerWithContext = (*warningRecorder)(nil)
+
+// HandleWarningHeaderWithContext records the warning text when the warn-code
+// is 299, the same as client-go's default warning handler.
+func (r *warningRecorder) HandleWarningHeaderWithContext(
+	ctx context.Context,
+	code int,
+	agent string,
+	text string,
+) {
+	if code != warningCodeMiscellaneous || text == "" {
+		return
+	}
+	debug.Printf(ctx, "kube: warning: %s", text)
+	r.Lock()
+	defer r.Unlock()
+	r.texts = append(r.texts, text)
+}
+
+// reset forgets all recorded warnings. It is called before the action is
+// executed so that warnings returned while auto-creating the namespace are
+// not counted.
+func (r *warningRecorder) reset() {
+	r.Lock()
+	defer r.Unlock()
+	r.texts = nil
+}
+
+// warnings returns the unique recorded warnings in the order they were
+// returned by the Kubernetes API server. The API server returns the same
+// warnings for every page or retry of a request, so duplicates are dropped.
+func (r *warningRecorder) warnings() []string {
+	r.Lock()
+	defer r.Unlock()
+	return lo.Uniq(r.texts)
+}
+
+// isDeprecationWarning returns true if the supplied warning text is about a
+// deprecated apiVersion or field, e.g. "batch/v1beta1 CronJob is deprecated
+// in v1.21+, unavailable in v1.25+; use batch/v1 CronJob".
+func isDeprecationWarning(text string) bool {
+	return strings.Contains(strings.ToLower(text), "deprecated")
+}
+
+// warningsOK returns true if the warnings returned by the Kubernetes API
+// server match the `assert.warnings` assertions and, when
+// `fail-on-deprecation` is set, none of them is a deprecation warning.
+func (a *assertions) warningsOK(ctx context.Context) bool {
+	res := true
+	if a.failOnDeprecation {
+		for _, w := range a.warnings {
+			if isDeprecationWarning(w) {
+				a.Fail(DeprecationWarning(w))
+				res = false
+			}
+		}
+	}
+	exp := a.exp
+	if exp == nil || exp.Warnings == nil {
+		return res
+	}
+	we := exp.Warnings
+	if we.None {
+		for _, w := range a.warnings {
+			a.Fail(UnexpectedWarning(w))
+			res = false
+		}
+	}
+	if we.Contains != nil {
+		for _, s := range replaceVariablesInStrings(ctx, we.Contains.Values()) {
+			found := lo.ContainsBy(a.warnings, func(w string) bool {
+				return strings.Contains(w, s)
+			})
+			if !found {
+				a.Fail(WarningNotFound(s, a.warnings))
+				res = false
+			}
+		}
+	}
+	if we.Count != nil && len(a.warnings) != *we.Count {
+		a.Fail(WarningCountNotEqual(*we.Count, a.warnings))
+		res = false
+	}
+	return res
+}
@@ -0,0 +1,353 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"time"
+
+	gdtcontext "github.com/gdt-dev/core/context"
+	"github.com/gdt-dev/core/debug"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	// WatchUntilDeleted is the `kube.watch.until` value indicating the watch
+	// should stop when the watched object is deleted.
+	WatchUntilDeleted = "deleted"
+)
+
+// WatchAction describes a watch on one or more resources that records the
+// stream of changes to those resources, similar to `kubectl get --watch`.
+type WatchAction struct {
+	// Target is a string or object identifying the resource(s) to watch. It
+	// takes the same forms as the argument to `kube.get`:
+	//
+	// - a string with a resource kind or kind alias, e.g. "pods", "po",
+	//   followed by a space or `/` character followed by the resource name.
+	// - an object with a `type` and optional `name` and `labels` fields
+	//   containing a label selector that should be used to select that `type`
+	//   of resource.
+	Target *ResourceIdentifier `yaml:"target"`
+	// Duration is a duration string (e.g. "30s") for how long to watch. If
+	// empty, the watch continues until the Until predicate holds or the test
+	// spec's timeout is about to be exceeded. It must be less than the test
+	// spec's timeout, less the margin the watch keeps for reporting. When the
+	// timeout stops the watch before the duration elapses, the test fails.
+	Duration string `yaml:"duration,omitempty"`
+	// Until is a terminal predicate that stops the watch. It is either the
+	// string `deleted`, which stops the watch when a watched object is
+	// deleted, or a `matches` fragment (a map[string]any, inline YAML string
+	// or file path) that stops the watch when a watched object matches it.
+	Until any `yaml:"until,omitempty"`
+}
+
+// Title returns the watch target's kind and name, if present
+func (w *WatchAction) Title() string {
+	if w.Target == nil {
+		return ""
+	}
+	return w.Target.Title()
+}
+
+// NewWatchAction returns a new WatchAction watching the target for the
+// supplied duration or until the supplied Until predicate holds.
+func NewWatchAction(
+	target *ResourceIdentifier,
+	duration string,
+	until any,
+) *WatchAction {
+	return &WatchAction{
+		Target:   target,
+		Duration: duration,
+		Until:    until,
+	}
+}
+
+// untilOK returns true if the supplied watch event satisfies the Until
+// predicate, false otherwise.
+func (w *WatchAction) untilOK(
+	ctx context.Context,
+	ev *watchEvent,
+) bool {
+	if w.Until == nil {
+		return false
+	}
+	if w.Until == WatchUntilDeleted {
+		return ev.typ == watch.Deleted
+	}
+	matchObj := matchObjectFromAny(ctx, w.Until)
+	return compareResourceToMatchObject(ev.obj, matchObj).Empty()
+}
+
+// watchEvent is a single ADDED, MODIFIED or DELETED event observed during a
+// `kube.watch`
+type watchEvent struct {
+	// typ is the watch event type
+	typ watch.EventType
+	// obj is the state of the object in the event
+	obj *unstructured.Unstructured
+}
+
+// watchOutput contains the events recorded during a `kube.watch`
+type watchOutput struct {
+	// events contains the recorded events, in the order they were observed
+	events []*watchEvent
+	// hasUntil indicates the watch had a terminal Until predicate
+	hasUntil bool
+	// untilReached indicates the Until predicate held for an observed event
+	untilReached bool
+	// duration is the watch's Duration, if any
+	duration string
+	// available is the time the test spec's timeout left for the watch, if
+	// the test spec has a timeout
+	available time.Duration
+	// truncated indicates the test spec's timeout stopped the watch before
+	// its Duration elapsed
+	truncated bool
+}
+
+// items returns the recorded events as a slice of objects with `type` and
+// `object` fields suitable for JSONPath lookups.
+func (o *watchOutput) items() []any {
+	res := make([]any, len(o.events))
+	for x, ev := range o.events {
+		res[x] = map[string]any{
+			"type":   string(ev.typ),
+			"object": ev.obj.Object,
+		}
+	}
+	return res
+}
+
+// count returns the number of recorded events of the supplied type
+func (o *watchOutput) count(typ watch.EventType) int {
+	n := 0
+	for _, ev := range o.events {
+		if ev.typ == typ {
+			n++
+		}
+	}
+	return n
+}
+
+// watch records ADDED, MODIFIED and DELETED events for the targeted
+// resource(s) until the watch duration elapses, the Until predicate holds or
+// the test spec's timeout is about to be exceeded, and populates `out` with a
+// `*watchOutput`. Objects that exist when the watch starts are recorded as
+// ADDED events.
+func (a *Action) watch(
+	ctx context.Context,
+	c *connection,
+	ns string,
+	out *interface{},
+) error {
+	target := a.Watch.Target
+	arg := target.Arg
+	argRep := gdtcontext.ReplaceVariables(ctx, arg)
+	if arg != argRep {
+		debug.Printf(
+			ctx,
+			"kube.watch: replaced arg: %s -> %s",
+			arg, argRep,
+		)
+	}
+	name := target.Name
+	nameRep := gdtcontext.ReplaceVariables(ctx, name)
+	if name != nameRep {
+		debug.Printf(
+			ctx,
+			"kube.watch: replaced name: %s -> %s",
+			name, nameRep,
+		)
+	}
+	res, err := c.gvrFromArg(argRep)
+	if err != nil {
+		return err
+	}
+	ri := resourceInterface(c, res, ns)
+	opts := targetListOptions(nameRep, target.Labels)
+	debug.Printf(
+		ctx, "kube.watch: %s (ns: %s, fields: %q, labels: %q)",
+		res.Resource, ns, opts.FieldSelector, opts.LabelSelector,
+	)
+
+	marginCtx, cancel := withReportMargin(ctx)
+	defer cancel()
+	watchCtx := marginCtx
+	wo := &watchOutput{
+		hasUntil: a.Watch.Until != nil,
+		duration: a.Watch.Duration,
+	}
+	if deadline, ok := marginCtx.Deadline(); ok {
+		wo.available = time.Until(deadline)
+	}
+	if a.Watch.Duration != "" {
+		// We validated the duration during parse
+		d, _ := time.ParseDuration(a.Watch.Duration)
+		if wo.available > 0 && wo.available < d && !wo.hasUntil {
+			// The effective timeout, which may come from the scenario's
+			// defaults or the plugin's default, cannot fit the duration, so
+			// there is no point watching a partial window.
+			debug.Printf(
+				ctx, "kube.watch: the test spec's timeout leaves %s, less "+
+					"than the %s duration",
+				wo.available, a.Watch.Duration,
+			)
+			wo.truncated = true
+			*out = wo
+			return nil
+		}
+		var durCancel context.CancelFunc
+		watchCtx, durCancel = context.WithTimeout(watchCtx, d)
+		defer durCancel()
+	}
+
+	record := func(ev *watchEvent) bool {
+		debug.Printf(
+			ctx, "kube.watch: %s %s/%s (resourceVersion: %s)",
+			ev.typ, res.Resource, ev.obj.GetName(),
+			ev.obj.GetResourceVersion(),
+		)
+		wo.events = append(wo.events, ev)
+		if a.Watch.untilOK(ctx, ev) {
+			wo.untilReached = true
+		}
+		return wo.untilReached
+	}
+
+	list, err := ri.List(ctx, opts)
+	if err != nil {
+		return err
+	}
+	for x := range list.Items {
+		if record(&watchEvent{typ: watch.Added, obj: &list.Items[x]}) {
+			*out = wo
+			return nil
+		}
+	}
+
+	w, err := newRetryWatcher(watchCtx, ri, list.GetResourceVersion(), opts)
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+	for {
+		select {
+		case <-watchCtx.Done():
+			if a.Watch.Duration != "" && marginCtx.Err() != nil {
+				// The recorded events only cover part of the duration the
+				// test author asked for, which matters for assertions like
+				// `assert.watch.added: 0`.
+				debug.Printf(
+					ctx, "kube.watch: stopped by the test spec's timeout "+
+						"before the %s duration elapsed",
+					a.Watch.Duration,
+				)
+				wo.truncated = true
+			}
+			*out = wo
+			return nil
+		case ev, ok := <-w.ResultChan():
+			if !ok {
+				*out = wo
+				return nil
+			}
+			switch ev.Type {
+			case watch.Added, watch.Modified, watch.Deleted:
+				obj, ok := ev.Object.(*unstructured.Unstructured)
+				if !ok {
+					continue
+				}
+				if record(&watchEvent{typ: ev.Type, obj: obj}) {
+					*out = wo
+					return nil
+				}
+			case watch.Error:
+				return apierrors.FromObject(ev.Object)
+			}
+		}
+	}
+}
+
+// watchOK returns true if the subject is the output of a `kube.watch` action
+// and matches the Watch conditions, false otherwise.
+func (a *assertions) watchOK(ctx context.Context) bool {
+	out, ok := a.r.(*watchOutput)
+	if !ok || out == nil {
+		return true
+	}
+	if out.truncated {
+		// The recorded events only cover part of the requested window, so
+		// assertions like `added: 0` or `never` cannot be trusted.
+		a.Fail(WatchDurationNotElapsed(out.duration, out.available))
+		return false
+	}
+	res := true
+	if out.hasUntil && !out.untilReached {
+		a.Fail(WatchUntilNotReached(len(out.events)))
+		res = false
+	}
+	exp := a.exp
+	if exp == nil || exp.Watch == nil {
+		return res
+	}
+	we := exp.Watch
+	if len(we.Sequence) > 0 {
+		pos := 0
+		for step, fragment := range we.Sequence {
+			// Each step in the sequence must be matched by an event that
+			// comes after the event that matched the previous step.
+			matchObj := matchObjectFromAny(ctx, fragment)
+			start := pos
+			found := false
+			for ; pos < len(out.events); pos++ {
+				delta := compareResourceToMatchObject(out.events[pos].obj, matchObj)
+				if delta.Empty() {
+					found = true
+					pos++
+					break
+				}
+			}
+			if !found {
+				a.Fail(WatchSequenceNotMatched(step, start, len(out.events)))
+				res = false
+				break
+			}
+		}
+	}
+	for x, fragment := range we.Never {
+		matchObj := matchObjectFromAny(ctx, fragment)
+		for y, ev := range out.events {
+			delta := compareResourceToMatchObject(ev.obj, matchObj)
+			if delta.Empty() {
+				a.Fail(WatchNeverMatched(x, y, string(ev.typ), ev.obj.GetName()))
+				res = false
+				break
+			}
+		}
+	}
+	counts := []struct {
+		typ watch.EventType
+		exp *int
+	}{
+		{watch.Added, we.Added},
+		{watch.Modified, we.Modified},
+		{watch.Deleted, we.Deleted},
+	}
+	for _, c := range counts {
+		if c.exp == nil {
+			continue
+		}
+		got := out.count(c.typ)
+		if got != *c.exp {
+			a.Fail(WatchEventCountNotEqual(string(c.typ), *c.exp, got))
+			res = false
+		}
+	}
+	return res
+}
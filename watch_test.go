@@ -0,0 +1,38 @@
+// Use and distribution licensed under the Apache license version 2.
+//
+// See the COPYING file in the root project directory for full text.
+
+package kube
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchOKDurationNotElapsed(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	zero := 0
+	exp := &Expect{Watch: &WatchExpect{Added: &zero}}
+	out := &watchOutput{
+		duration:  "10s",
+		available: 4500 * time.Millisecond,
+		truncated: true,
+	}
+	a := newAssertions(nil, exp, nil, out, false)
+	require.False(a.OK(context.TODO()))
+	require.Len(a.Failures(), 1)
+	assert.ErrorIs(a.Failures()[0], ErrWatchDurationNotElapsed)
+	assert.ErrorContains(
+		a.Failures()[0], "the test spec's timeout left 4.5s to watch for 10s",
+	)
+
+	out.truncated = false
+	a = newAssertions(nil, exp, nil, out, false)
+	assert.True(a.OK(context.TODO()))
+}